@@ -50,6 +50,7 @@ func (b *Backend) ToNative(ctx context.Context, cfg proto.Message, opts netjsonc
 	if err != nil {
 		return nil, err
 	}
+	domainCfg.Syntax = resolveRenderSyntax(opts)
 	
 	// Convert domain model to AST
 	doc, err := domainCfg.ToAST()
@@ -58,7 +59,15 @@ func (b *Backend) ToNative(ctx context.Context, cfg proto.Message, opts netjsonc
 	}
 	
 	// Render AST to Bundle
-	return b.renderer.Render(ctx, doc, opts)
+	bundle, err := b.renderer.Render(ctx, doc, opts)
+	if err != nil {
+		return nil, err
+	}
+	recordSyntax(bundle, domainCfg.Syntax)
+	if opts.TargetVersion != "" {
+		bundle.Metadata.Custom[MetadataTargetVersionKey] = opts.TargetVersion
+	}
+	return bundle, nil
 }
 
 // ToNetJSON converts UCI configuration back to NetJSON.
@@ -71,7 +80,7 @@ func (b *Backend) ToNetJSON(ctx context.Context, bundle *netjsonconfig.Bundle, o
 	}
 	
 	// Convert AST to domain model
-	cfg, err := domain.FromAST(doc)
+	cfg, err := domain.FromASTWithSyntax(doc, resolveParseSyntax(opts, bundle, doc))
 	if err != nil {
 		return nil, err
 	}
@@ -79,3 +88,62 @@ func (b *Backend) ToNetJSON(ctx context.Context, bundle *netjsonconfig.Bundle, o
 	// Convert domain model to proto
 	return cfg.ToProto()
 }
+
+// MetadataSyntaxKey is the Bundle.Metadata.Custom key recording the UCI syntax
+// ("dsa" or "legacy") a bundle was rendered with.
+const MetadataSyntaxKey = "uci_syntax"
+
+// MetadataTargetVersionKey is the Bundle.Metadata.Custom key recording the
+// RenderOptions.TargetVersion hint a bundle was rendered for. Metadata.Version is
+// left to the caller.
+const MetadataTargetVersionKey = "target_version"
+
+// resolveRenderSyntax picks the syntax to render.
+// An explicit mode wins; RenderModeAuto uses the TargetVersion hint and
+// falls back to DSA. Rendering has no input bundle, so bundle metadata hints
+// (Metadata.Version, MetadataTargetVersionKey) only apply when parsing.
+func resolveRenderSyntax(opts netjsonconfig.RenderOptions) domain.Syntax {
+	switch opts.Mode {
+	case netjsonconfig.RenderModeLegacy:
+		return domain.SyntaxLegacy
+	case netjsonconfig.RenderModeDSA:
+		return domain.SyntaxDSA
+	}
+	if syntax, ok := domain.SyntaxForVersion(opts.TargetVersion); ok {
+		return syntax
+	}
+	return domain.SyntaxDSA
+}
+
+// resolveParseSyntax picks the syntax used to interpret parsed UCI.
+// Precedence: explicit ParseOptions.Mode, a syntax recorded in the bundle
+// metadata, a version hint (ParseOptions.SourceMetadata["version"],
+// Bundle.Metadata.Version or the recorded target version), then detection from
+// the document content.
+func resolveParseSyntax(opts netjsonconfig.ParseOptions, bundle *netjsonconfig.Bundle, doc *uci.Document) domain.Syntax {
+	switch opts.Mode {
+	case netjsonconfig.RenderModeLegacy:
+		return domain.SyntaxLegacy
+	case netjsonconfig.RenderModeDSA:
+		return domain.SyntaxDSA
+	}
+	switch bundle.Metadata.Custom[MetadataSyntaxKey] {
+	case domain.SyntaxLegacy.String():
+		return domain.SyntaxLegacy
+	case domain.SyntaxDSA.String():
+		return domain.SyntaxDSA
+	}
+	for _, version := range []string{opts.SourceMetadata["version"], bundle.Metadata.Version, bundle.Metadata.Custom[MetadataTargetVersionKey]} {
+		if syntax, ok := domain.SyntaxForVersion(version); ok {
+			return syntax
+		}
+	}
+	return domain.DetectSyntax(doc)
+}
+
+func recordSyntax(bundle *netjsonconfig.Bundle, syntax domain.Syntax) {
+	if bundle.Metadata.Custom == nil {
+		bundle.Metadata.Custom = make(map[string]string)
+	}
+	bundle.Metadata.Custom[MetadataSyntaxKey] = syntax.String()
+}
@@ -37,12 +37,13 @@ type backendEntry struct {
 func main() {
 	var (
 		mode         = flag.String("mode", "render", "operation mode: render | parse")
-		backendName  = flag.String("backend", "", "backend name (openwrt|openvpn|wireguard|vxlan)")
-		inputPath    = flag.String("input", "", "input path (default: stdin)")
+		backendName  = flag.String("backend", "", "backend name (openwrt|openwrt-batch|openvpn|wireguard|vxlan)")
+		inputPath    = flag.String("input", "", "input path or root directory containing etc/config (default: stdin)")
 		configPaths  = flag.String("configs", "", "comma-separated config files to merge (first has lowest priority)")
 		outputPath   = flag.String("output", "", "output path (default: stdout)")
 		filesOutDir  = flag.String("files-dir", "", "directory for additional files (render mode)")
 		prettyJSON   = flag.Bool("pretty", true, "pretty print JSON in parse mode")
+		targetVer    = flag.String("target-version", "", "firmware version hint used to pick the syntax (e.g. 19.07.10)")
 		listBackends = flag.Bool("list-backends", false, "list supported backends")
 	)
 	flag.Parse()
@@ -78,7 +79,7 @@ func main() {
 		if err := unmarshal.Unmarshal(payload, message); err != nil {
 			exitWithError(fmt.Errorf("decode netjson: %w", err))
 		}
-		bundle, err := entry.backend.ToNative(ctx, message, netjsonconfig.RenderOptions{})
+		bundle, err := entry.backend.ToNative(ctx, message, netjsonconfig.RenderOptions{TargetVersion: *targetVer})
 		if err != nil {
 			exitWithError(fmt.Errorf("render: %w", err))
 		}
@@ -96,14 +97,15 @@ func main() {
 			}
 		}
 	case "parse":
-		data, err := readInput(*inputPath)
+		bundle, err := loadParseInput(*inputPath)
 		if err != nil {
 			exitWithError(fmt.Errorf("read input: %w", err))
 		}
-		bundle := &netjsonconfig.Bundle{
-			Packages: []netjsonconfig.Package{{Name: "main", Content: data}},
+		parseOpts := netjsonconfig.ParseOptions{}
+		if *targetVer != "" {
+			parseOpts.SourceMetadata = map[string]string{"version": *targetVer}
 		}
-		msg, err := entry.backend.ToNetJSON(ctx, bundle, netjsonconfig.ParseOptions{})
+		msg, err := entry.backend.ToNetJSON(ctx, bundle, parseOpts)
 		if err != nil {
 			exitWithError(fmt.Errorf("parse: %w", err))
 		}
@@ -129,7 +131,15 @@ func buildRegistry() map[string]backendEntry {
 		"openwrt": {
 			backend: openwrtbackend.New(
 				ucirenderer.NewPlainTextRenderer(),
-				ucirenderer.NewNotImplementedParser(),
+				ucirenderer.NewPlainTextParser(),
+			),
+			newMessage: func() proto.Message { return &openwrtv1.OpenWrtConfig{} },
+		},
+		// 输出 uci batch 脚本而非完整的 /etc/config 文件
+		"openwrt-batch": {
+			backend: openwrtbackend.New(
+				ucirenderer.NewBatchRenderer(),
+				ucirenderer.NewPlainTextParser(),
 			),
 			newMessage: func() proto.Message { return &openwrtv1.OpenWrtConfig{} },
 		},
@@ -171,6 +181,30 @@ func readInput(path string) ([]byte, error) {
 	return os.ReadFile(path)
 }
 
+// loadParseInput 构造 parse 模式的输入 bundle。
+// 目录被视为路由器根文件系统（etc/config/* 为各个包）；单个文件以文件名（去掉扩展名）
+// 作为包名，与 /etc/config/<package> 一致。标准输入没有文件名，使用占位包名 main，
+// 此时内容需要包含 package 行。
+func loadParseInput(path string) (*netjsonconfig.Bundle, error) {
+	if path != "" && path != "-" {
+		if info, err := os.Stat(path); err == nil && info.IsDir() {
+			return netjsonconfig.LoadBundleDir(path, netjsonconfig.LoadOptions{})
+		}
+	}
+	data, err := readInput(path)
+	if err != nil {
+		return nil, err
+	}
+	name := "main"
+	if path != "" && path != "-" {
+		base := filepath.Base(path)
+		name = strings.TrimSuffix(base, filepath.Ext(base))
+	}
+	return &netjsonconfig.Bundle{
+		Packages: []netjsonconfig.Package{{Name: name, Content: data}},
+	}, nil
+}
+
 // loadAndMergeConfigs 加载并合并多个配置文件。
 // 支持两种方式：
 // 1. 使用 -configs 参数指定多个文件（逗号分隔）
@@ -80,7 +80,15 @@ type directiveValue struct {
 	hasValue bool
 }
 
-func buildOpenvpnDirectives(inst *openvpnv1.OpenVpnInstance) ([]ast.Directive, error) {
+// zeroAllowedKeys 列出取值为 0 时仍需输出的数值选项，其余数值为 0 视为未设置。
+var zeroAllowedKeys = map[string]struct{}{
+	"script_security": {},
+}
+
+// InstanceOptions 将实例展开为规范化的选项表，OpenVPN 后端与 OpenWrt UCI 共用：
+// remote 变为 "host port proto" 列表，data_ciphers 变为冒号分隔的字符串，
+// 字符串数组（如 push）变为 []string，不在 zeroAllowedKeys 中的数值 0 被移除。
+func InstanceOptions(inst *openvpnv1.OpenVpnInstance) (map[string]any, error) {
 	marshaller := protojson.MarshalOptions{
 		UseProtoNames:   true,
 		EmitUnpopulated: false,
@@ -106,6 +114,38 @@ func buildOpenvpnDirectives(inst *openvpnv1.OpenVpnInstance) ([]ast.Directive, e
 		delete(raw, "status_version")
 	}
 
+	for key, value := range raw {
+		if number, ok := value.(float64); ok && number == 0 {
+			if _, allowed := zeroAllowedKeys[key]; !allowed {
+				delete(raw, key)
+			}
+			continue
+		}
+		items, ok := value.([]any)
+		if !ok {
+			continue
+		}
+		var list []string
+		for _, item := range items {
+			if text := strings.TrimSpace(asString(item)); text != "" {
+				list = append(list, text)
+			}
+		}
+		if len(list) == 0 {
+			delete(raw, key)
+			continue
+		}
+		raw[key] = list
+	}
+	return raw, nil
+}
+
+func buildOpenvpnDirectives(inst *openvpnv1.OpenVpnInstance) ([]ast.Directive, error) {
+	raw, err := InstanceOptions(inst)
+	if err != nil {
+		return nil, err
+	}
+
 	values := make(map[string][]directiveValue)
 	keys := make([]string, 0, len(raw))
 	for key := range raw {
@@ -113,12 +153,13 @@ func buildOpenvpnDirectives(inst *openvpnv1.OpenVpnInstance) ([]ast.Directive, e
 	}
 	sort.Strings(keys)
 
-	zeroAllowed := map[string]struct{}{
-		"script_security": {},
-	}
 	emptyFlagKeys := map[string]struct{}{
 		"server_bridge": {},
 	}
+	// push 的参数包含空格，需加引号
+	quotedKeys := map[string]struct{}{
+		"push": {},
+	}
 
 	for _, key := range keys {
 		value := raw[key]
@@ -132,11 +173,6 @@ func buildOpenvpnDirectives(inst *openvpnv1.OpenVpnInstance) ([]ast.Directive, e
 			}
 			appendDirective(values, key, strings.TrimSpace(typed), true)
 		case float64:
-			if typed == 0 {
-				if _, ok := zeroAllowed[key]; !ok {
-					continue
-				}
-			}
 			appendDirective(values, key, formatNumber(typed), true)
 		case bool:
 			if typed {
@@ -148,6 +184,9 @@ func buildOpenvpnDirectives(inst *openvpnv1.OpenVpnInstance) ([]ast.Directive, e
 				if item == "" {
 					continue
 				}
+				if _, ok := quotedKeys[key]; ok {
+					item = quoteValue(item)
+				}
 				appendDirective(values, key, item, true)
 			}
 		default:
@@ -173,6 +212,11 @@ func buildOpenvpnDirectives(inst *openvpnv1.OpenVpnInstance) ([]ast.Directive, e
 	return directives, nil
 }
 
+// quoteValue 按 OpenVPN 配置语法加双引号，仅转义反斜杠与双引号。
+func quoteValue(value string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(value) + `"`
+}
+
 func appendDirective(values map[string][]directiveValue, key, value string, hasValue bool) {
 	values[key] = append(values[key], directiveValue{
 		value:    value,
@@ -250,6 +294,38 @@ func normalizeDataCiphers(raw map[string]any) {
 	raw["data_ciphers"] = strings.Join(ciphers, ":")
 }
 
+// ParseRemote 解析 normalizeRemote 生成的 "host [port] [proto]" 字符串。
+func ParseRemote(line string) *openvpnv1.Remote {
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return nil
+	}
+	remote := &openvpnv1.Remote{Host: fields[0]}
+	if len(fields) > 1 {
+		if port, err := strconv.ParseUint(fields[1], 10, 32); err == nil {
+			remote.Port = uint32(port)
+		}
+	}
+	if len(fields) > 2 {
+		remote.Proto = fields[2]
+	}
+	return remote
+}
+
+// ParseDataCiphers 解析冒号分隔的 data_ciphers，"?" 前缀表示 optional。
+func ParseDataCiphers(value string) []*openvpnv1.DataCipher {
+	var ciphers []*openvpnv1.DataCipher
+	for _, item := range strings.Split(value, ":") {
+		item = strings.TrimSpace(item)
+		cipher, optional := strings.CutPrefix(item, "?")
+		if cipher == "" {
+			continue
+		}
+		ciphers = append(ciphers, &openvpnv1.DataCipher{Cipher: cipher, Optional: optional})
+	}
+	return ciphers
+}
+
 func asString(value any) string {
 	switch v := value.(type) {
 	case string:
@@ -0,0 +1,95 @@
+package openwrt
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	devicev1 "github.com/honeybbq/netjson/gen/go/netjson/device/v1"
+	openwrtv1 "github.com/honeybbq/netjson/gen/go/netjson/openwrt/v1"
+
+	helpers "github.com/honeybbq/netjsonconfig/domain/utils"
+	"github.com/honeybbq/netjsonconfig/pkg/ast/uci"
+)
+
+// staticIPv4Addresses returns the IPv4 addresses written to ipaddr, in order.
+func staticIPv4Addresses(iface *devicev1.Interface) []*devicev1.InterfaceAddress {
+	var addrs []*devicev1.InterfaceAddress
+	for _, addr := range iface.GetAddresses() {
+		family := addr.GetFamily()
+		if (family != "ipv4" && family != "") || addr.GetProto() == "dhcp" || addr.GetAddress() == "" {
+			continue
+		}
+		addrs = append(addrs, addr)
+	}
+	return addrs
+}
+
+// validateInterfaceAddresses checks that the IPv4 addresses of an interface agree on the
+// gateway: netifd only takes a single gateway option per interface.
+func validateInterfaceAddresses(msg *openwrtv1.OpenWrtConfig) error {
+	for _, iface := range msg.GetInterfaces() {
+		gateway := ""
+		for _, addr := range staticIPv4Addresses(iface) {
+			gw := addr.GetGateway()
+			if gw == "" {
+				continue
+			}
+			if ip := net.ParseIP(gw); ip == nil || ip.To4() == nil {
+				return validationError(fmt.Sprintf("interface %q", iface.GetName()), "gateway %q of %s is not an IPv4 address", gw, addr.GetAddress())
+			}
+			if gateway != "" && gw != gateway {
+				return validationError(fmt.Sprintf("interface %q", iface.GetName()), "conflicting gateways %q and %q", gateway, gw)
+			}
+			gateway = gw
+		}
+	}
+	return nil
+}
+
+// buildLegacyAliasSections splits multiple IPv4 addresses for legacy syntax: the first
+// one stays on the interface as ipaddr/netmask, every other one becomes an alias
+// interface <iface>_alias<n> attached with ifname '@<iface>'.
+func buildLegacyAliasSections(section *uci.Section, iface *devicev1.Interface) []*uci.Section {
+	addrs := staticIPv4Addresses(iface)
+	if len(addrs) < 2 {
+		return nil
+	}
+	section.Delete("ipaddr")
+	setLegacyAddress(section, addrs[0])
+
+	var aliases []*uci.Section
+	for idx, addr := range addrs[1:] {
+		alias := uci.NewSection("interface", fmt.Sprintf("%s_alias%d", iface.GetName(), idx+1))
+		helpers.SetString(alias, "ifname", "@"+iface.GetName())
+		helpers.SetString(alias, "proto", "static")
+		setLegacyAddress(alias, addr)
+		aliases = append(aliases, alias)
+	}
+	return aliases
+}
+
+func setLegacyAddress(section *uci.Section, addr *devicev1.InterfaceAddress) {
+	helpers.SetString(section, "ipaddr", addr.GetAddress())
+	if mask := addr.GetMask(); mask != 0 {
+		helpers.SetString(section, "netmask", prefixToNetmask(mask))
+	}
+}
+
+// legacyAliasParent returns the interface a generated alias section belongs to. Only
+// static aliases carrying nothing but an address are folded back; anything else stays
+// a regular interface.
+func legacyAliasParent(section *uci.Section) (string, bool) {
+	parent, ok := strings.CutPrefix(helpers.GetString(section, "ifname"), "@")
+	if !ok || parent == "" || helpers.GetString(section, "proto") != "static" {
+		return "", false
+	}
+	for _, e := range section.Entries {
+		switch e.Key {
+		case "ifname", "proto", "ipaddr", "netmask":
+		default:
+			return "", false
+		}
+	}
+	return parent, true
+}
@@ -1,17 +1,23 @@
 package openwrt
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net"
+	"slices"
 	"strconv"
 	"strings"
 
+	"google.golang.org/protobuf/proto"
+
+	commonv1 "github.com/honeybbq/netjson/gen/go/netjson/common/v1"
 	devicev1 "github.com/honeybbq/netjson/gen/go/netjson/device/v1"
 	openvpnv1 "github.com/honeybbq/netjson/gen/go/netjson/openvpn/v1"
 	openwrtv1 "github.com/honeybbq/netjson/gen/go/netjson/openwrt/v1"
 	zerotierv1 "github.com/honeybbq/netjson/gen/go/netjson/zerotier/v1"
 
+	openvpndomain "github.com/honeybbq/netjsonconfig/domain/openvpn"
 	helpers "github.com/honeybbq/netjsonconfig/domain/utils"
 	"github.com/honeybbq/netjsonconfig/pkg/ast/uci"
 	"github.com/honeybbq/netjsonconfig/pkg/nxerrors"
@@ -20,6 +26,8 @@ import (
 // Config 表示 OpenWrt 领域模型。
 type Config struct {
 	Message *openwrtv1.OpenWrtConfig
+	// Syntax 选择 ToAST 生成的 UCI 方言，零值为 DSA。
+	Syntax Syntax
 }
 
 // FromProto 构造领域模型。
@@ -39,22 +47,70 @@ func (c *Config) ToAST() (*uci.Document, error) {
 		return nil, nxerrors.New(nxerrors.KindInternal, errors.New("openwrt message is nil"))
 	}
 
+	// validate and allocate once; buildInterfaceSection renders from this allocation
+	vnis, err := vxlanVNIs(c.Message)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateInterfaceProtocols(c.Message); err != nil {
+		return nil, err
+	}
+	if err := validateVirtualDevices(c.Message); err != nil {
+		return nil, err
+	}
+	if err := validateInterfaceAddresses(c.Message); err != nil {
+		return nil, err
+	}
+	if err := validateWireless(c.Message, c.Syntax); err != nil {
+		return nil, err
+	}
+	if err := validateTimezone(c.Message); err != nil {
+		return nil, err
+	}
+
 	var packages []*uci.Package
 	if pkg := buildSystemPackage(c.Message); pkg != nil {
 		packages = append(packages, pkg)
 	}
-	if pkg := buildWirelessPackage(c.Message); pkg != nil {
+	if pkg := buildWirelessPackage(c.Message, c.Syntax); pkg != nil {
 		packages = append(packages, pkg)
 	}
-	if pkg := buildNetworkPackage(c.Message); pkg != nil {
+	if pkg := buildNetworkPackage(c.Message, c.Syntax, vnis); pkg != nil {
 		packages = append(packages, pkg)
 	}
-	if pkg := buildOpenvpnPackage(c.Message); pkg != nil {
-		packages = append(packages, pkg)
+	openvpn, err := buildOpenvpnPackage(c.Message)
+	if err != nil {
+		return nil, err
 	}
-	if pkg := buildZerotierPackage(c.Message); pkg != nil {
-		packages = append(packages, pkg)
+	if openvpn != nil {
+		packages = append(packages, openvpn)
 	}
+	zerotier, zerotierFiles, err := buildZerotierPackage(c.Message)
+	if err != nil {
+		return nil, err
+	}
+	if zerotier != nil {
+		packages = append(packages, zerotier)
+	}
+	firewall, err := buildFirewallPackage(c.Message)
+	if err != nil {
+		return nil, err
+	}
+	if firewall != nil {
+		packages = append(packages, firewall)
+	}
+	dhcp, err := buildDhcpPackage(c.Message)
+	if err != nil {
+		return nil, err
+	}
+	if dhcp != nil {
+		packages = append(packages, dhcp)
+	}
+	custom, err := buildCustomPackages(c.Message)
+	if err != nil {
+		return nil, err
+	}
+	packages = append(packages, custom...)
 
 	if len(packages) == 0 {
 		return nil, nxerrors.New(nxerrors.KindRender, fmt.Errorf("no supported netjson fields found"))
@@ -62,7 +118,7 @@ func (c *Config) ToAST() (*uci.Document, error) {
 
 	return &uci.Document{
 		Packages: packages,
-		Files:    c.Message.GetFiles(),
+		Files:    append(slices.Clone(c.Message.GetFiles()), zerotierFiles...),
 	}, nil
 }
 
@@ -90,7 +146,7 @@ func buildSystemPackage(msg *openwrtv1.OpenWrtConfig) *uci.Package {
 	}
 }
 
-func buildNetworkPackage(msg *openwrtv1.OpenWrtConfig) *uci.Package {
+func buildNetworkPackage(msg *openwrtv1.OpenWrtConfig, syntax Syntax, vnis map[string]uint32) *uci.Package {
 	if msg == nil {
 		return nil
 	}
@@ -101,6 +157,11 @@ func buildNetworkPackage(msg *openwrtv1.OpenWrtConfig) *uci.Package {
 	}
 	sections = append(sections, buildSwitchSections(msg.GetSwitches())...)
 
+	if syntax == SyntaxLegacy {
+		sections = append(sections, buildLegacyInterfaceSections(msg, vnis)...)
+		return finishNetworkPackage(msg, sections)
+	}
+
 	// DSA style: build device sections first, then bridge-vlan, then interface sections
 	for _, iface := range msg.GetInterfaces() {
 		if iface.GetWireless() != nil {
@@ -125,10 +186,15 @@ func buildNetworkPackage(msg *openwrtv1.OpenWrtConfig) *uci.Package {
 		if iface.GetWireless() != nil {
 			continue
 		}
-		if section := buildInterfaceSection(iface, msg); section != nil {
+		if section := buildInterfaceSection(iface, msg, vnis); section != nil {
 			sections = append(sections, section)
 		}
 	}
+	return finishNetworkPackage(msg, sections)
+}
+
+// finishNetworkPackage appends the sections shared by all syntaxes.
+func finishNetworkPackage(msg *openwrtv1.OpenWrtConfig, sections []*uci.Section) *uci.Package {
 	sections = append(sections, buildWireguardPeerSections(msg.GetWireguardPeers())...)
 	sections = append(sections, buildRouteSections(msg.GetRoutes())...)
 	sections = append(sections, buildRuleSections(msg.GetIpRules())...)
@@ -163,6 +229,9 @@ func buildDeviceSection(iface *devicev1.Interface) *uci.Section {
 		return nil
 	}
 
+	if isVirtualDevice(iface) {
+		return buildVirtualDeviceSection(iface)
+	}
 	isBridge := strings.EqualFold(iface.GetType(), "bridge")
 	if !isBridge {
 		return nil
@@ -182,18 +251,30 @@ func buildDeviceSection(iface *devicev1.Interface) *uci.Section {
 		helpers.AppendList(section, "ports", member)
 	}
 
-	// MTU goes to device section
-	helpers.SetUint32Ptr(section, "mtu", iface.Mtu)
-
-	// Bridge-specific L2 options (from Interface directly)
-	helpers.SetBool(section, "stp", iface.Stp)
-	helpers.SetBool(section, "igmp_snooping", iface.IgmpSnooping)
+	// MTU and L2 options go to device section
+	applyBridgeOptions(section, iface)
 
 	// Enable VLAN filtering if configured
 	if len(iface.GetVlanFiltering()) > 0 {
 		helpers.SetBoolValue(section, "vlan_filtering", true)
 	}
 
+	if len(section.Entries) == 0 {
+		return nil
+	}
+
+	return section
+}
+
+// applyBridgeOptions writes bridge MTU and L2 options. DSA puts them on the
+// device section, legacy syntax on the interface section itself.
+func applyBridgeOptions(section *uci.Section, iface *devicev1.Interface) {
+	helpers.SetUint32Ptr(section, "mtu", iface.Mtu)
+
+	// Bridge-specific L2 options (from Interface directly)
+	helpers.SetBool(section, "stp", iface.Stp)
+	helpers.SetBool(section, "igmp_snooping", iface.IgmpSnooping)
+
 	// Bridge-specific L2 options (from BridgeSettings if present)
 	if bridge := iface.GetBridge(); bridge != nil {
 		helpers.SetUint32Ptr(section, "forward_delay", bridge.ForwardDelay)
@@ -208,12 +289,6 @@ func buildDeviceSection(iface *devicev1.Interface) *uci.Section {
 		helpers.SetUint32Ptr(section, "hash_max", bridge.HashMax)
 		helpers.SetUint32Ptr(section, "robustness", bridge.Robustness)
 	}
-
-	if len(section.Options) == 0 && len(section.Lists) == 0 {
-		return nil
-	}
-
-	return section
 }
 
 // buildBridgeVlanSections creates bridge-vlan sections for VLAN filtering (DSA).
@@ -255,14 +330,18 @@ func buildBridgeVlanSections(iface *devicev1.Interface) []*uci.Section {
 				continue
 			}
 			// Format: "eth0:t" or "eth1:u" (tagged/untagged)
-			portStr := fmt.Sprintf("%s:%s", port.GetIfname(), port.GetTagging())
+			flags := port.GetTagging()
 			if port.GetPrimaryVid() {
-				portStr += "*" // Primary VID marker
+				flags += "*" // Primary VID marker
+			}
+			portStr := port.GetIfname()
+			if flags != "" {
+				portStr += ":" + flags
 			}
 			helpers.AppendList(section, "ports", portStr)
 		}
 
-		if len(section.Options) > 0 || len(section.Lists) > 0 {
+		if len(section.Entries) > 0 {
 			bridgeVlanSections = append(bridgeVlanSections, section)
 		}
 
@@ -279,28 +358,38 @@ func buildBridgeVlanSections(iface *devicev1.Interface) []*uci.Section {
 	return append(bridgeVlanSections, vlanInterfaces...)
 }
 
-func buildInterfaceSection(iface *devicev1.Interface, msg *openwrtv1.OpenWrtConfig) *uci.Section {
-	if iface == nil || iface.GetName() == "" {
+// buildInterfaceSection renders the interface section; vnis is the allocation returned
+// by vxlanVNIs.
+func buildInterfaceSection(iface *devicev1.Interface, msg *openwrtv1.OpenWrtConfig, vnis map[string]uint32) *uci.Section {
+	if iface == nil || iface.GetName() == "" || isDeviceOnly(iface) {
 		return nil
 	}
 	section := uci.NewSection("interface", iface.GetName())
 	isWireguard := strings.EqualFold(iface.GetType(), "wireguard")
 	isBridge := strings.EqualFold(iface.GetType(), "bridge")
+	isVxlan := isVxlanInterface(iface)
+	isVirtual := isVirtualDevice(iface)
+	protocol := iface.GetProto()
+	// tunnels get their device from netifd, modems from their settings
+	ownDevice := isWireguard || isVxlan || isTunnelProto(protocol) || isModemProto(protocol)
 
 	// DSA style: set device option
 	if isBridge {
 		// Bridge references the device section: br-xxx
 		bridgeName := fmt.Sprintf("br-%s", iface.GetName())
 		helpers.SetString(section, "device", bridgeName)
-	} else if !isWireguard {
-		// Non-bridge, non-wireguard: use device name or interface name
+	} else if isVirtual {
+		// Virtual devices are referenced by the name of their device section
+		helpers.SetString(section, "device", virtualDeviceName(iface))
+	} else if !ownDevice {
+		// Non-bridge, non-tunnel: use device name or interface name
 		device := iface.GetDevice()
 		if device == "" {
 			device = iface.GetName()
 		}
 		helpers.SetString(section, "device", device)
-	} else {
-		// Wireguard: set device if provided
+	} else if !isModemProto(protocol) {
+		// Tunnels: set device if provided
 		helpers.SetString(section, "device", iface.GetDevice())
 	}
 
@@ -314,8 +403,8 @@ func buildInterfaceSection(iface *devicev1.Interface, msg *openwrtv1.OpenWrtConf
 	helpers.SetStringPtr(section, "ip6ifaceid", iface.Ip6Ifaceid)
 	helpers.SetStringPtr(section, "ip6gw", iface.Ip6Gateway)
 	helpers.SetStringPtr(section, "zone", iface.FirewallZone)
-	// MTU for bridges goes to device section (DSA), only set here for non-bridges
-	if !isBridge {
+	// MTU for bridges and virtual devices goes to the device section
+	if !isBridge && !isVirtual {
 		helpers.SetUint32Ptr(section, "mtu", iface.Mtu)
 	}
 	helpers.SetUint32Ptr(section, "metric", iface.Metric)
@@ -331,7 +420,7 @@ func buildInterfaceSection(iface *devicev1.Interface, msg *openwrtv1.OpenWrtConf
 	helpers.SetBool(section, "sourcefilter", iface.SourceFilter)
 	helpers.SetString(section, "fwmark", iface.GetFwmark())
 
-	if iface.Mac != nil && *iface.Mac != "" {
+	if iface.Mac != nil && *iface.Mac != "" && !isVirtual {
 		helpers.SetString(section, "macaddr", *iface.Mac)
 	}
 
@@ -350,13 +439,21 @@ func buildInterfaceSection(iface *devicev1.Interface, msg *openwrtv1.OpenWrtConf
 		helpers.SetString(section, "proto", "wireguard")
 	}
 
-	applyInterfaceAddresses(section, iface, isWireguard, isBridge)
+	switch {
+	case isVxlan:
+		// vxlan carries no addresses itself; they go on a bridge or interface using it
+		applyVxlanInterface(section, iface, vnis[iface.GetName()])
+	case hasProtocolSettings(protocol):
+		applyProtocolSettings(section, iface)
+	default:
+		applyInterfaceAddresses(section, iface, isWireguard, isBridge)
+	}
 	applyDNS(section, iface, msg)
 	if isWireguard {
 		applyWireguardInterface(section, iface)
 	}
 
-	if len(section.Options) == 0 && len(section.Lists) == 0 {
+	if len(section.Entries) == 0 {
 		return nil
 	}
 	return section
@@ -364,6 +461,8 @@ func buildInterfaceSection(iface *devicev1.Interface, msg *openwrtv1.OpenWrtConf
 
 func applyInterfaceAddresses(section *uci.Section, iface *devicev1.Interface, isWireguard, isBridge bool) {
 	ifaceProtoSet := helpers.OptionExists(section, "proto")
+	// several static IPv4 addresses are rendered as list ipaddr in CIDR form
+	multiIPv4 := len(staticIPv4Addresses(iface)) > 1
 	for _, addr := range iface.GetAddresses() {
 		family := addr.GetFamily()
 		switch family {
@@ -376,14 +475,15 @@ func applyInterfaceAddresses(section *uci.Section, iface *devicev1.Interface, is
 				continue
 			}
 			if addr.GetAddress() != "" {
+				value := addr.GetAddress()
+				if mask := addr.GetMask(); mask != 0 {
+					value = fmt.Sprintf("%s/%d", value, mask)
+				}
 				if isWireguard {
-					value := addr.GetAddress()
-					if mask := addr.GetMask(); mask != 0 {
-						value = fmt.Sprintf("%s/%d", value, mask)
-					}
 					helpers.AppendList(section, "addresses", value)
+				} else if multiIPv4 {
+					helpers.AppendList(section, "ipaddr", value)
 				} else {
-					// DSA style: all interfaces use option ipaddr (not list)
 					helpers.SetString(section, "ipaddr", addr.GetAddress())
 					if mask := addr.GetMask(); mask != 0 {
 						if netmask := prefixToNetmask(mask); netmask != "" {
@@ -426,12 +526,8 @@ func applyDNS(section *uci.Section, iface *devicev1.Interface, msg *openwrtv1.Op
 	ifaceDnsSearch := iface.GetDnsSearch()
 
 	// Check if proto should ignore global DNS
-	proto := section.Options["proto"]
-	ignoreGlobalDNS := false
-	if len(proto) > 0 {
-		p := proto[0]
-		ignoreGlobalDNS = p == "dhcp" || p == "dhcpv6" || p == "none"
-	}
+	proto, _ := section.Option("proto")
+	ignoreGlobalDNS := proto == "dhcp" || proto == "dhcpv6" || proto == "none"
 
 	// Apply DNS servers (space-separated string, not list)
 	var dnsServers []string
@@ -632,14 +728,14 @@ func buildWireguardPeerSections(peers []*openwrtv1.WireguardPeerConfig) []*uci.S
 	return sections
 }
 
-func buildWirelessPackage(msg *openwrtv1.OpenWrtConfig) *uci.Package {
+func buildWirelessPackage(msg *openwrtv1.OpenWrtConfig, syntax Syntax) *uci.Package {
 	if msg == nil {
 		return nil
 	}
 
 	var sections []*uci.Section
 	for _, radio := range msg.GetRadios() {
-		if section := buildWifiDeviceSection(radio); section != nil {
+		if section := buildWifiDeviceSection(radio, syntax); section != nil {
 			sections = append(sections, section)
 		}
 	}
@@ -662,16 +758,27 @@ func buildWirelessPackage(msg *openwrtv1.OpenWrtConfig) *uci.Package {
 	}
 }
 
-func buildWifiDeviceSection(radio *devicev1.Radio) *uci.Section {
+// buildWifiDeviceSection renders a radio. Targets before 21.02 select the band through
+// hwmode; a channels list makes the radio pick its channel automatically among them.
+func buildWifiDeviceSection(radio *devicev1.Radio, syntax Syntax) *uci.Section {
 	if radio == nil || radio.GetName() == "" {
 		return nil
 	}
 	section := uci.NewSection("wifi-device", radio.GetName())
 
-	section.Options["type"] = []string{"mac80211"}
-	helpers.SetString(section, "band", radio.GetBand())
+	section.SetOption("type", "mac80211")
+	if syntax == SyntaxLegacy {
+		helpers.SetString(section, "hwmode", legacyHwmode(radio.GetBand()))
+	} else {
+		helpers.SetString(section, "band", radio.GetBand())
+	}
 	if channel := radio.GetChannel(); channel != 0 {
 		helpers.SetUint32Value(section, "channel", channel)
+	} else if len(radio.GetChannels()) > 0 {
+		helpers.SetString(section, "channel", "auto")
+		for _, channel := range radio.GetChannels() {
+			helpers.AppendList(section, "channels", strconv.FormatUint(uint64(channel), 10))
+		}
 	}
 	helpers.SetString(section, "htmode", radio.GetHtmode())
 	helpers.SetString(section, "country", radio.GetCountry())
@@ -714,9 +821,10 @@ func buildWifiIfaceSection(interfaceName string, wifi *devicev1.WirelessSettings
 		helpers.SetList(section, "maclist", wifi.GetMaclist())
 	}
 	helpers.SetString(section, "ifname", interfaceName)
+	applyMeshSettings(section, wifi)
 
 	setWirelessNetwork(section, wifi.GetNetwork())
-	applyWirelessEncryption(section, wifi.GetEncryption())
+	applyWirelessEncryption(section, wifi.GetEncryption(), wifi.GetMode())
 
 	return section
 }
@@ -745,7 +853,10 @@ func setWirelessNetwork(section *uci.Section, networks []string) {
 	helpers.SetList(section, "network", filtered)
 }
 
-func applyWirelessEncryption(section *uci.Section, enc *devicev1.WirelessEncryption) {
+// applyWirelessEncryption writes the encryption options. Enterprise modes add the
+// 802.1X settings: the RADIUS servers for access points, the EAP credentials for
+// stations.
+func applyWirelessEncryption(section *uci.Section, enc *devicev1.WirelessEncryption, mode string) {
 	if enc == nil {
 		return
 	}
@@ -756,10 +867,19 @@ func applyWirelessEncryption(section *uci.Section, enc *devicev1.WirelessEncrypt
 	helpers.SetString(section, "cipher", enc.GetCipher())
 	helpers.SetString(section, "ieee80211w", enc.GetIeee80211W())
 	helpers.SetString(section, "key", enc.GetKey())
-	helpers.SetString(section, "server", enc.GetServer())
-	helpers.SetUint32Ptr(section, "port", enc.Port)
-	helpers.SetString(section, "acct_server", enc.GetAcctServer())
-	helpers.SetUint32Ptr(section, "acct_port", enc.AcctServerPort)
+	if isEnterpriseEncryption(encryption) {
+		if mapWirelessMode(mode) == "sta" {
+			applyEapCredentials(section, enc)
+		} else {
+			applyRadiusSettings(section, enc)
+		}
+	} else {
+		helpers.SetString(section, "server", enc.GetServer())
+		helpers.SetUint32Ptr(section, "port", enc.Port)
+		helpers.SetString(section, "acct_server", enc.GetAcctServer())
+		helpers.SetUint32Ptr(section, "acct_port", enc.AcctServerPort)
+	}
+	helpers.SetString(section, "owe_transition_ifname", enc.GetOweTransitionIfname())
 	helpers.SetBool(section, "disabled", enc.Disabled)
 }
 
@@ -796,6 +916,18 @@ func mapEncryptionProtocol(proto string) string {
 		return "sae"
 	case "wpa3_personal_mixed":
 		return "sae-mixed"
+	case "wpa_enterprise":
+		return "wpa"
+	case "wpa2_enterprise":
+		return "wpa2"
+	case "wpa2_enterprise_mixed", "wpa_enterprise_mixed":
+		return "wpa-mixed"
+	case "wpa3_enterprise":
+		return "wpa3"
+	case "wpa3_enterprise_mixed":
+		return "wpa3-mixed"
+	case "owe", "wpa3_owe":
+		return "owe"
 	default:
 		return proto
 	}
@@ -820,6 +952,34 @@ func sanitizeIdentifier(value string) string {
 	return clean
 }
 
+// validationError 构造 "<scope>: <message>" 形式的校验错误，scope 指明出错的配置块，
+// 例如 firewall 或 interface "lan"。
+func validationError(scope, format string, args ...any) error {
+	return nxerrors.New(nxerrors.KindValidation, fmt.Errorf("%s: %s", scope, fmt.Sprintf(format, args...)))
+}
+
+// newNamedSection names sections <type>_<name> (or <type>_<n>), adding a suffix when
+// two entries share a name, as the stock firewall and dhcp configs often do.
+func newNamedSection(taken map[string]struct{}, typ, preferred string, idx int) *uci.Section {
+	base := typ + "_" + sanitizeSectionName("", preferred, idx)
+	name := base
+	for suffix := 2; ; suffix++ {
+		if _, ok := taken[name]; !ok {
+			break
+		}
+		name = fmt.Sprintf("%s_%d", base, suffix)
+	}
+	taken[name] = struct{}{}
+	return uci.NewSection(typ, name)
+}
+
+// newOptionSection is newNamedSection with every populated field of msg written as an option.
+func newOptionSection(taken map[string]struct{}, typ, preferred string, idx int, msg proto.Message) *uci.Section {
+	section := newNamedSection(taken, typ, preferred, idx)
+	helpers.ApplyOptionsFromMap(section, helpers.ProtoMessageToMap(msg), nil)
+	return section
+}
+
 func buildSwitchSections(switches []*openwrtv1.SwitchConfig) []*uci.Section {
 	var sections []*uci.Section
 	for idx, sw := range switches {
@@ -869,9 +1029,13 @@ func buildGeneralSection(general *devicev1.General) *uci.Section {
 	delete(values, "ula_prefix")
 	delete(values, "globals_id")
 
-	helpers.ApplyOptionsFromMap(section, values, nil)
+	helpers.ApplyOptionsFromMap(section, values, map[string]struct{}{
+		"timezone": {},
+		"zonename": {},
+	})
+	applyTimezone(section, general)
 
-	if len(section.Options) == 0 && len(section.Lists) == 0 {
+	if len(section.Entries) == 0 {
 		return nil
 	}
 	return section
@@ -889,7 +1053,7 @@ func buildNtpSection(ntp *openwrtv1.NtpSettings) *uci.Section {
 	helpers.SetUint32Ptr(section, "port", ntp.Port)
 	helpers.SetList(section, "pools", ntp.GetPools())
 
-	if len(section.Options) == 0 && len(section.Lists) == 0 {
+	if len(section.Entries) == 0 {
 		return nil
 	}
 	return section
@@ -924,9 +1088,9 @@ func buildLedSections(leds []*openwrtv1.Led) []*uci.Section {
 	return sections
 }
 
-func buildOpenvpnPackage(msg *openwrtv1.OpenWrtConfig) *uci.Package {
+func buildOpenvpnPackage(msg *openwrtv1.OpenWrtConfig) (*uci.Package, error) {
 	if msg == nil || len(msg.GetOpenvpn()) == 0 {
-		return nil
+		return nil, nil
 	}
 
 	var sections []*uci.Section
@@ -934,29 +1098,38 @@ func buildOpenvpnPackage(msg *openwrtv1.OpenWrtConfig) *uci.Package {
 		if vpn == nil || vpn.GetName() == "" {
 			continue
 		}
-		if section := buildOpenvpnSection(vpn); section != nil {
+		section, err := buildOpenvpnSection(vpn)
+		if err != nil {
+			return nil, err
+		}
+		if section != nil {
 			sections = append(sections, section)
 		}
 	}
 
 	if len(sections) == 0 {
-		return nil
+		return nil, nil
 	}
 
 	return &uci.Package{
 		Name:     "openvpn",
 		Sections: sections,
-	}
+	}, nil
 }
 
-func buildOpenvpnSection(vpn *openvpnv1.OpenVpnInstance) *uci.Section {
-	if vpn == nil {
-		return nil
-	}
-
+// buildOpenvpnSection renders an instance with the options normalised by the OpenVPN
+// backend: remote and push become lists, data_ciphers a colon separated string and
+// zero numbers are dropped the same way. False booleans are left out since the init
+// script treats any set flag as enabled.
+func buildOpenvpnSection(vpn *openvpnv1.OpenVpnInstance) (*uci.Section, error) {
 	name := sanitizeIdentifier(vpn.GetName())
 	if name == "" {
-		return nil
+		return nil, nil
+	}
+
+	values, err := openvpndomain.InstanceOptions(vpn)
+	if err != nil {
+		return nil, err
 	}
 
 	section := uci.NewSection("openvpn", name)
@@ -964,42 +1137,79 @@ func buildOpenvpnSection(vpn *openvpnv1.OpenVpnInstance) *uci.Section {
 	// enabled field defaults to true in OpenWrt
 	helpers.SetBoolValue(section, "enabled", true)
 
-	// Use ProtoMessageToMap for remaining fields
-	values := helpers.ProtoMessageToMap(vpn)
-	skip := map[string]struct{}{
-		"name": {},
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	for _, key := range keys {
+		switch v := values[key].(type) {
+		case string:
+			helpers.SetString(section, key, v)
+		case bool:
+			if v {
+				helpers.SetBoolValue(section, key, true)
+			}
+		case float64:
+			helpers.SetString(section, key, strconv.FormatFloat(v, 'f', -1, 64))
+		case []string:
+			helpers.SetList(section, key, v)
+		}
 	}
-	helpers.ApplyOptionsFromMap(section, values, skip)
-
-	return section
+	return section, nil
 }
 
-func buildZerotierPackage(msg *openwrtv1.OpenWrtConfig) *uci.Package {
+// Defaults used by the OpenWISP ZeroTier integration when the instance leaves them unset.
+const (
+	defaultZerotierConfigPath = "/etc/openwisp/zerotier"
+	zerotierLocalConfName     = "zerotier.conf"
+)
+
+// buildZerotierPackage renders one zerotier section per instance followed by the
+// network sections of its joined networks. Instances with local_conf also produce the
+// local.conf JSON file referenced by local_conf_path.
+func buildZerotierPackage(msg *openwrtv1.OpenWrtConfig) (*uci.Package, []*commonv1.IncludedFile, error) {
 	if msg == nil || len(msg.GetZerotier()) == 0 {
-		return nil
+		return nil, nil, nil
 	}
 
 	var sections []*uci.Section
+	var files []*commonv1.IncludedFile
+	networks := make(map[string]struct{})
 	for _, zt := range msg.GetZerotier() {
 		if zt == nil || zt.GetName() == "" {
 			continue
 		}
 		// Main zerotier section
-		if section := buildZerotierSection(zt); section != nil {
-			sections = append(sections, section)
+		section := buildZerotierSection(zt)
+		if section == nil {
+			continue
+		}
+		sections = append(sections, section)
+		// Network sections (for ZeroTier >= 1.14)
+		networkSections, err := buildZerotierNetworkSections(zt, networks)
+		if err != nil {
+			return nil, nil, err
+		}
+		sections = append(sections, networkSections...)
+
+		if zt.GetLocalConf() != nil {
+			file, err := buildZerotierLocalConf(zt)
+			if err != nil {
+				return nil, nil, err
+			}
+			files = append(files, file)
 		}
-		// Network sections (for ZeroTier > 1.14)
-		sections = append(sections, buildZerotierNetworkSections(zt)...)
 	}
 
 	if len(sections) == 0 {
-		return nil
+		return nil, nil, nil
 	}
 
 	return &uci.Package{
 		Name:     "zerotier",
 		Sections: sections,
-	}
+	}, files, nil
 }
 
 func buildZerotierSection(zt *zerotierv1.ZerotierNetwork) *uci.Section {
@@ -1013,31 +1223,181 @@ func buildZerotierSection(zt *zerotierv1.ZerotierNetwork) *uci.Section {
 	}
 
 	section := uci.NewSection("zerotier", name)
+	helpers.SetBoolValue(section, "enabled", !zt.GetDisabled())
 
-	// enabled field (inverse of disabled) - TODO: check if zerotier has disabled field
-	helpers.SetBoolValue(section, "enabled", true)
+	configPath := zt.GetConfigPath()
+	if configPath == "" {
+		configPath = defaultZerotierConfigPath
+	}
+	helpers.SetString(section, "config_path", configPath)
+	copyConfigPath := true
+	if zt.CopyConfigPath != nil {
+		copyConfigPath = zt.GetCopyConfigPath()
+	}
+	helpers.SetBoolValue(section, "copy_config_path", copyConfigPath)
 
-	helpers.SetString(section, "config_path", "/etc/openwisp/zerotier")
-	helpers.SetBoolValue(section, "copy_config_path", true)
+	helpers.SetString(section, "secret", zt.GetSecret())
+	helpers.SetUint32Ptr(section, "port", zt.Port)
+	if zt.GetLocalConf() != nil {
+		helpers.SetString(section, "local_conf_path", zerotierLocalConfPath(zt))
+	}
 
-	// join list - network IDs from all networks
-	// TODO: This needs to be implemented based on zerotier proto structure
+	var join []string
+	for _, network := range zt.GetNetworks() {
+		if id := network.GetId(); id != "" {
+			join = append(join, id)
+		}
+	}
+	helpers.SetList(section, "join", join)
 
 	return section
 }
 
-func buildZerotierNetworkSections(zt *zerotierv1.ZerotierNetwork) []*uci.Section {
-	// TODO: Implement network sections for ZeroTier > 1.14
-	// Each network should be a separate section of type "network"
-	return nil
+// buildZerotierNetworkSections renders the per-network sections read by ZeroTier >= 1.14.
+// The ifname only selects the section name; without it sections are named
+// owzt<last 6 digits of the id>.
+func buildZerotierNetworkSections(zt *zerotierv1.ZerotierNetwork, taken map[string]struct{}) ([]*uci.Section, error) {
+	var sections []*uci.Section
+	for idx, network := range zt.GetNetworks() {
+		id := strings.ToLower(network.GetId())
+		if !validZerotierNetworkID(id) {
+			return nil, nxerrors.New(nxerrors.KindValidation, fmt.Errorf("zerotier %q: network #%d has invalid id %q (want 16 hex digits)", zt.GetName(), idx+1, network.GetId()))
+		}
+		name := sanitizeIdentifier(network.GetIfname())
+		if name == "" {
+			name = zerotierIfname(id)
+		}
+		if _, ok := taken[name]; ok {
+			return nil, nxerrors.New(nxerrors.KindValidation, fmt.Errorf("zerotier %q: duplicate network %q", zt.GetName(), name))
+		}
+		taken[name] = struct{}{}
+
+		section := uci.NewSection("network", name)
+		helpers.SetString(section, "id", id)
+		helpers.SetBool(section, "allow_managed", network.AllowManaged)
+		helpers.SetBool(section, "allow_global", network.AllowGlobal)
+		helpers.SetBool(section, "allow_default", network.AllowDefault)
+		helpers.SetBool(section, "allow_dns", network.AllowDns)
+		sections = append(sections, section)
+	}
+	return sections, nil
+}
+
+func validZerotierNetworkID(id string) bool {
+	if len(id) != 16 {
+		return false
+	}
+	for _, r := range id {
+		if !strings.ContainsRune("0123456789abcdef", r) {
+			return false
+		}
+	}
+	return true
+}
+
+func zerotierIfname(id string) string {
+	return "owzt" + id[len(id)-6:]
+}
+
+func zerotierLocalConfPath(zt *zerotierv1.ZerotierNetwork) string {
+	if path := zt.GetLocalConfPath(); path != "" {
+		return path
+	}
+	configPath := zt.GetConfigPath()
+	if configPath == "" {
+		configPath = defaultZerotierConfigPath
+	}
+	return strings.TrimSuffix(configPath, "/") + "/" + zerotierLocalConfName
+}
+
+// zerotierSettings mirrors the "settings" object of ZeroTier's local.conf.
+type zerotierSettings struct {
+	PrimaryPort              *uint32  `json:"primaryPort,omitempty"`
+	SecondaryPort            *uint32  `json:"secondaryPort,omitempty"`
+	TertiaryPort             *uint32  `json:"tertiaryPort,omitempty"`
+	PortMappingEnabled       *bool    `json:"portMappingEnabled,omitempty"`
+	AllowSecondaryPort       *bool    `json:"allowSecondaryPort,omitempty"`
+	AllowTcpFallbackRelay    *bool    `json:"allowTcpFallbackRelay,omitempty"`
+	InterfacePrefixBlacklist []string `json:"interfacePrefixBlacklist,omitempty"`
+	Bind                     []string `json:"bind,omitempty"`
+	AllowManagementFrom      []string `json:"allowManagementFrom,omitempty"`
+}
+
+type zerotierLocalConf struct {
+	Settings zerotierSettings `json:"settings"`
 }
 
-// FromAST 根据 UCI 文档重建领域模型。
+func buildZerotierLocalConf(zt *zerotierv1.ZerotierNetwork) (*commonv1.IncludedFile, error) {
+	conf := zt.GetLocalConf()
+	data, err := json.MarshalIndent(zerotierLocalConf{Settings: zerotierSettings{
+		PrimaryPort:              conf.PrimaryPort,
+		SecondaryPort:            conf.SecondaryPort,
+		TertiaryPort:             conf.TertiaryPort,
+		PortMappingEnabled:       conf.PortMappingEnabled,
+		AllowSecondaryPort:       conf.AllowSecondaryPort,
+		AllowTcpFallbackRelay:    conf.AllowTcpFallbackRelay,
+		InterfacePrefixBlacklist: conf.GetInterfacePrefixBlacklist(),
+		Bind:                     conf.GetBind(),
+		AllowManagementFrom:      conf.GetAllowManagementFrom(),
+	}}, "", "    ")
+	if err != nil {
+		return nil, nxerrors.New(nxerrors.KindRender, fmt.Errorf("zerotier %q: encode local.conf: %w", zt.GetName(), err))
+	}
+	return &commonv1.IncludedFile{
+		Path:     zerotierLocalConfPath(zt),
+		Mode:     "0644",
+		Contents: string(data) + "\n",
+	}, nil
+}
+
+// FromAST 根据 UCI 文档重建领域模型，方言由 DetectSyntax 自动识别。
 func FromAST(doc *uci.Document) (*Config, error) {
+	return FromASTWithSyntax(doc, DetectSyntax(doc))
+}
+
+// FromASTWithSyntax 按指定方言解析 UCI 文档。
+func FromASTWithSyntax(doc *uci.Document, syntax Syntax) (*Config, error) {
 	if doc == nil {
 		return nil, nxerrors.New(nxerrors.KindParse, fmt.Errorf("document is nil"))
 	}
-	return nil, nxerrors.ErrNotImplemented
+
+	msg := &openwrtv1.OpenWrtConfig{}
+	for _, pkg := range doc.Packages {
+		if pkg == nil {
+			continue
+		}
+		switch pkg.Name {
+		case "system":
+			parseSystemPackage(pkg, msg)
+		case "network":
+			parseNetworkPackage(pkg, msg, syntax)
+		case "wireless":
+			parseWirelessPackage(pkg, msg)
+		case "openvpn":
+			parseOpenvpnPackage(pkg, msg)
+		case "zerotier":
+			parseZerotierPackage(pkg, msg)
+		case "firewall":
+			parseFirewallPackage(pkg, msg)
+		case "dhcp":
+			parseDhcpPackage(pkg, msg)
+		case placeholderPackage:
+			if len(pkg.Sections) > 0 {
+				first := pkg.Sections[0]
+				return nil, nxerrors.NewAt(nxerrors.KindParse, pkg.Name, first.Pos.Line,
+					fmt.Errorf("config %s appears before any package line; add a package line", first.Type))
+			}
+		default:
+			msg.Packages = append(msg.Packages, parseCustomPackage(pkg))
+		}
+	}
+	msg.Files = parseZerotierLocalConf(msg, doc.Files)
+
+	if proto.Size(msg) == 0 {
+		return nil, nxerrors.New(nxerrors.KindParse, fmt.Errorf("no supported uci sections found"))
+	}
+
+	return &Config{Message: msg, Syntax: syntax}, nil
 }
 
 // ToProto 输出 NetJSON proto。
@@ -1045,5 +1405,8 @@ func (c *Config) ToProto() (*openwrtv1.OpenWrtConfig, error) {
 	if c == nil {
 		return nil, nxerrors.New(nxerrors.KindInternal, fmt.Errorf("config is nil"))
 	}
-	return nil, nxerrors.ErrNotImplemented
+	if c.Message == nil {
+		return nil, nxerrors.New(nxerrors.KindInternal, errors.New("openwrt message is nil"))
+	}
+	return c.Message, nil
 }
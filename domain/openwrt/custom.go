@@ -0,0 +1,181 @@
+package openwrt
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"google.golang.org/protobuf/types/known/structpb"
+
+	openwrtv1 "github.com/honeybbq/netjson/gen/go/netjson/openwrt/v1"
+
+	"github.com/honeybbq/netjsonconfig/pkg/ast/uci"
+)
+
+// Section keys of custom packages, as in python netjsonconfig.
+const (
+	customTypeKey = "config_name"
+	customNameKey = "config_value"
+)
+
+// managedPackages are rendered and parsed from the typed model; custom packages cannot
+// use these names since parsing would route them to the typed parsers.
+var managedPackages = []string{"system", "network", "wireless", "openvpn", "zerotier", "firewall", "dhcp"}
+
+// placeholderPackage is the package name given to input without package lines when
+// there is no file name to take it from (stdin, single-document bundles). Sections
+// outside any package line are not attributable, so parsing rejects them.
+const placeholderPackage = "main"
+
+// buildCustomPackages renders the untyped packages verbatim. Each section is an object
+// with config_name (the section type), an optional config_value (the section name;
+// anonymous when missing) and its options: strings, numbers and booleans become
+// options, arrays become lists.
+func buildCustomPackages(msg *openwrtv1.OpenWrtConfig) ([]*uci.Package, error) {
+	var packages []*uci.Package
+	seen := make(map[string]struct{})
+	for idx, custom := range msg.GetPackages() {
+		name := custom.GetName()
+		if !validUciName(name, "-") {
+			return nil, validationError("custom package", "package #%d has invalid name %q", idx+1, name)
+		}
+		if slices.Contains(managedPackages, name) {
+			return nil, validationError("custom package", "package %q is generated from the typed configuration", name)
+		}
+		if _, ok := seen[name]; ok {
+			return nil, validationError("custom package", "duplicate package %q", name)
+		}
+		seen[name] = struct{}{}
+
+		pkg := &uci.Package{Name: name}
+		counters := make(map[string]int)
+		names := make(map[string]struct{})
+		for sectionIdx, fields := range custom.GetSections() {
+			section, err := buildCustomSection(fields.GetFields(), counters)
+			if err != nil {
+				return nil, validationError("custom package", "package %q section #%d: %v", name, sectionIdx+1, err)
+			}
+			if _, ok := names[section.Name]; ok {
+				return nil, validationError("custom package", "package %q has duplicate section %q", name, section.Name)
+			}
+			names[section.Name] = struct{}{}
+			pkg.Sections = append(pkg.Sections, section)
+		}
+		packages = append(packages, pkg)
+	}
+	return packages, nil
+}
+
+func buildCustomSection(fields map[string]*structpb.Value, counters map[string]int) (*uci.Section, error) {
+	typ := fields[customTypeKey].GetStringValue()
+	if !validUciName(typ, "") {
+		return nil, fmt.Errorf("%s %q is not a valid section type", customTypeKey, typ)
+	}
+	section := uci.NewSection(typ, fields[customNameKey].GetStringValue())
+	if section.Name != "" && !validUciName(section.Name, "") {
+		return nil, fmt.Errorf("%s %q is not a valid section name", customNameKey, section.Name)
+	}
+	if section.Name == "" {
+		// same naming the parser gives anonymous sections
+		section.Name = fmt.Sprintf("%s_%d", typ, counters[typ])
+		section.Anonymous = true
+	}
+	counters[typ]++
+
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		if key != customTypeKey && key != customNameKey {
+			keys = append(keys, key)
+		}
+	}
+	slices.Sort(keys)
+	for _, key := range keys {
+		if !validUciName(key, "") {
+			return nil, fmt.Errorf("%q is not a valid option name", key)
+		}
+		value := fields[key]
+		if list := value.GetListValue(); list != nil {
+			for _, item := range list.GetValues() {
+				text, err := customScalar(item)
+				if err != nil {
+					return nil, fmt.Errorf("list %s: %w", key, err)
+				}
+				section.AddList(key, text)
+			}
+			continue
+		}
+		if _, ok := value.GetKind().(*structpb.Value_NullValue); ok {
+			continue
+		}
+		text, err := customScalar(value)
+		if err != nil {
+			return nil, fmt.Errorf("option %s: %w", key, err)
+		}
+		section.SetOption(key, text)
+	}
+	return section, nil
+}
+
+// customScalar formats a JSON scalar the way UCI expects it: booleans as 1/0 and
+// integral numbers without a fraction.
+func customScalar(value *structpb.Value) (string, error) {
+	switch kind := value.GetKind().(type) {
+	case *structpb.Value_StringValue:
+		return kind.StringValue, nil
+	case *structpb.Value_BoolValue:
+		if kind.BoolValue {
+			return "1", nil
+		}
+		return "0", nil
+	case *structpb.Value_NumberValue:
+		return strconv.FormatFloat(kind.NumberValue, 'f', -1, 64), nil
+	default:
+		return "", fmt.Errorf("only strings, numbers, booleans and lists of them are supported")
+	}
+}
+
+// validUciName reports names made of letters, digits, underscores and the extra runes.
+func validUciName(name, extra string) bool {
+	if name == "" {
+		return false
+	}
+	for _, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_':
+		case strings.ContainsRune(extra, r):
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// parseCustomPackage restores a package the typed model does not know. UCI values are
+// untyped, so every option comes back as a string and every list as an array of strings.
+func parseCustomPackage(pkg *uci.Package) *openwrtv1.UciPackage {
+	custom := &openwrtv1.UciPackage{Name: pkg.Name}
+	for _, section := range pkg.Sections {
+		fields := map[string]*structpb.Value{
+			customTypeKey: structpb.NewStringValue(section.Type),
+		}
+		if !section.Anonymous && section.Name != "" {
+			fields[customNameKey] = structpb.NewStringValue(section.Name)
+		}
+		for _, e := range section.Entries {
+			switch e.Kind {
+			case uci.KindList:
+				list := fields[e.Key].GetListValue()
+				if list == nil {
+					list = &structpb.ListValue{}
+					fields[e.Key] = structpb.NewListValue(list)
+				}
+				list.Values = append(list.Values, structpb.NewStringValue(e.Value))
+			default:
+				fields[e.Key] = structpb.NewStringValue(e.Value)
+			}
+		}
+		custom.Sections = append(custom.Sections, &structpb.Struct{Fields: fields})
+	}
+	return custom
+}
@@ -0,0 +1,218 @@
+package openwrt
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	devicev1 "github.com/honeybbq/netjson/gen/go/netjson/device/v1"
+	openwrtv1 "github.com/honeybbq/netjson/gen/go/netjson/openwrt/v1"
+
+	helpers "github.com/honeybbq/netjsonconfig/domain/utils"
+	"github.com/honeybbq/netjsonconfig/pkg/ast/uci"
+)
+
+// Interface types rendered as netifd virtual devices (config device + type).
+const (
+	device8021q   = "8021q"
+	device8021ad  = "8021ad"
+	deviceMacvlan = "macvlan"
+	deviceVeth    = "veth"
+)
+
+// maxIfnameLen is IFNAMSIZ minus the trailing NUL.
+const maxIfnameLen = 15
+
+func virtualDeviceType(iface *devicev1.Interface) string {
+	switch typ := strings.ToLower(iface.GetType()); typ {
+	case device8021q, device8021ad, deviceMacvlan, deviceVeth:
+		return typ
+	default:
+		return ""
+	}
+}
+
+func isVirtualDevice(iface *devicev1.Interface) bool {
+	return virtualDeviceType(iface) != ""
+}
+
+func isVlanDeviceType(typ string) bool {
+	return typ == device8021q || typ == device8021ad
+}
+
+// virtualDeviceName returns the kernel name of the device: the interface device when
+// set, otherwise <ifname>.<vid> for VLANs and the interface name for macvlan/veth.
+func virtualDeviceName(iface *devicev1.Interface) string {
+	if device := iface.GetDevice(); device != "" {
+		return device
+	}
+	if isVlanDeviceType(virtualDeviceType(iface)) {
+		vlan := iface.GetVlan()
+		return fmt.Sprintf("%s.%d", vlan.GetIfname(), vlan.GetVid())
+	}
+	return iface.GetName()
+}
+
+// isDeviceOnly reports virtual devices without a logical interface: no proto and no
+// addresses, e.g. a VLAN device only used as a bridge port.
+func isDeviceOnly(iface *devicev1.Interface) bool {
+	return isVirtualDevice(iface) && iface.GetProto() == "" && len(iface.GetAddresses()) == 0
+}
+
+// validateVirtualDevices checks the settings of 8021q/8021ad, macvlan and veth interfaces
+// and that their device names are unique.
+func validateVirtualDevices(msg *openwrtv1.OpenWrtConfig) error {
+	names := make(map[string]string)
+	for _, iface := range msg.GetInterfaces() {
+		typ := virtualDeviceType(iface)
+		if typ == "" {
+			continue
+		}
+		if err := validateVirtualDevice(iface, typ); err != nil {
+			return validationError(fmt.Sprintf("interface %q", iface.GetName()), "%v", err)
+		}
+		name := virtualDeviceName(iface)
+		if len(name) > maxIfnameLen {
+			return validationError(fmt.Sprintf("interface %q", iface.GetName()), "device name %q is longer than %d characters", name, maxIfnameLen)
+		}
+		if other, ok := names[name]; ok {
+			return validationError(fmt.Sprintf("interface %q", iface.GetName()), "device %q already defined by %q", name, other)
+		}
+		names[name] = iface.GetName()
+	}
+	return nil
+}
+
+func validateVirtualDevice(iface *devicev1.Interface, typ string) error {
+	switch typ {
+	case device8021q, device8021ad:
+		vlan := iface.GetVlan()
+		if vlan.GetIfname() == "" {
+			return fmt.Errorf("%s requires the parent ifname", typ)
+		}
+		if vid := vlan.GetVid(); vid == 0 || vid > 4094 {
+			return fmt.Errorf("vid %d out of range 1-4094", vid)
+		}
+		for _, mapping := range append(vlan.GetIngressQosMapping(), vlan.GetEgressQosMapping()...) {
+			if !validQosMapping(mapping) {
+				return fmt.Errorf("invalid qos mapping %q (want <from>:<to>)", mapping)
+			}
+		}
+	case deviceMacvlan:
+		macvlan := iface.GetMacvlan()
+		if macvlan.GetIfname() == "" {
+			return fmt.Errorf("macvlan requires the parent ifname")
+		}
+		switch macvlan.GetMode() {
+		case "", "private", "vepa", "bridge", "passthru":
+		default:
+			return fmt.Errorf("invalid macvlan mode %q (want private, vepa, bridge or passthru)", macvlan.GetMode())
+		}
+	case deviceVeth:
+		peer := iface.GetVeth().GetPeerName()
+		if peer == "" {
+			return fmt.Errorf("veth requires peer_name")
+		}
+		if len(peer) > maxIfnameLen {
+			return fmt.Errorf("veth peer_name %q is longer than %d characters", peer, maxIfnameLen)
+		}
+	}
+	return nil
+}
+
+// validQosMapping accepts the "<from>:<to>" priority pairs of ip-link(8).
+func validQosMapping(mapping string) bool {
+	from, to, ok := strings.Cut(mapping, ":")
+	if !ok {
+		return false
+	}
+	for _, value := range []string{from, to} {
+		if _, err := strconv.ParseUint(value, 10, 32); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// buildVirtualDeviceSection renders the device section of a virtual device interface.
+// Like bridges, the section is named device_<interface>; MAC address and MTU belong
+// to the device.
+func buildVirtualDeviceSection(iface *devicev1.Interface) *uci.Section {
+	typ := virtualDeviceType(iface)
+	section := uci.NewSection("device", "device_"+iface.GetName())
+	helpers.SetString(section, "name", virtualDeviceName(iface))
+	helpers.SetString(section, "type", typ)
+	switch typ {
+	case device8021q, device8021ad:
+		vlan := iface.GetVlan()
+		helpers.SetString(section, "ifname", vlan.GetIfname())
+		helpers.SetUint32Value(section, "vid", vlan.GetVid())
+		helpers.SetList(section, "ingress_qos_mapping", vlan.GetIngressQosMapping())
+		helpers.SetList(section, "egress_qos_mapping", vlan.GetEgressQosMapping())
+	case deviceMacvlan:
+		macvlan := iface.GetMacvlan()
+		helpers.SetString(section, "ifname", macvlan.GetIfname())
+		helpers.SetString(section, "mode", macvlan.GetMode())
+	case deviceVeth:
+		veth := iface.GetVeth()
+		helpers.SetString(section, "peer_name", veth.GetPeerName())
+		helpers.SetStringPtr(section, "peer_macaddr", veth.PeerMacaddr)
+	}
+	helpers.SetStringPtr(section, "macaddr", iface.Mac)
+	helpers.SetUint32Ptr(section, "mtu", iface.Mtu)
+	return section
+}
+
+// isVirtualDeviceSection reports device sections written by buildVirtualDeviceSection.
+func isVirtualDeviceSection(section *uci.Section) bool {
+	switch helpers.GetString(section, "type") {
+	case device8021q, device8021ad, deviceMacvlan, deviceVeth:
+		return true
+	default:
+		return false
+	}
+}
+
+// parseVirtualDevice restores the device settings into iface. The device name is only
+// kept when it differs from the default virtualDeviceName would pick.
+func parseVirtualDevice(section *uci.Section, iface *devicev1.Interface) {
+	typ := helpers.GetString(section, "type")
+	iface.Type = typ
+	switch typ {
+	case device8021q, device8021ad:
+		iface.Vlan = &devicev1.VlanDeviceSettings{
+			Ifname:            helpers.GetString(section, "ifname"),
+			Vid:               helpers.GetUint32Value(section, "vid"),
+			IngressQosMapping: splitValues(section, "ingress_qos_mapping"),
+			EgressQosMapping:  splitValues(section, "egress_qos_mapping"),
+		}
+	case deviceMacvlan:
+		iface.Macvlan = &devicev1.MacvlanSettings{
+			Ifname: helpers.GetString(section, "ifname"),
+			Mode:   helpers.GetString(section, "mode"),
+		}
+	case deviceVeth:
+		iface.Veth = &devicev1.VethSettings{
+			PeerName:    helpers.GetString(section, "peer_name"),
+			PeerMacaddr: helpers.GetStringPtr(section, "peer_macaddr"),
+		}
+	}
+	if name := helpers.GetString(section, "name"); name != virtualDeviceName(iface) {
+		iface.Device = name
+	}
+	iface.Mac = helpers.GetStringPtr(section, "macaddr")
+	iface.Mtu = helpers.GetUint32Ptr(section, "mtu")
+}
+
+// parseDeviceOnly restores a virtual device no interface section refers to. The
+// interface name comes from the device_<interface> section name, falling back to
+// the device name.
+func parseDeviceOnly(section *uci.Section) *devicev1.Interface {
+	name := helpers.GetString(section, "name")
+	if base, ok := strings.CutPrefix(section.Name, "device_"); ok && !section.Anonymous {
+		name = base
+	}
+	iface := &devicev1.Interface{Name: name}
+	parseVirtualDevice(section, iface)
+	return iface
+}
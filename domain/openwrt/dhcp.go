@@ -0,0 +1,223 @@
+package openwrt
+
+import (
+	"net"
+
+	"google.golang.org/protobuf/proto"
+
+	devicev1 "github.com/honeybbq/netjson/gen/go/netjson/device/v1"
+	openwrtv1 "github.com/honeybbq/netjson/gen/go/netjson/openwrt/v1"
+
+	helpers "github.com/honeybbq/netjsonconfig/domain/utils"
+	"github.com/honeybbq/netjsonconfig/pkg/ast/uci"
+)
+
+// Stock OpenWrt pool on a /24: .100 - .249.
+const (
+	defaultDhcpStart = 100
+	defaultDhcpLimit = 150
+)
+
+// buildDhcpPackage renders /etc/config/dhcp from the dhcp block: the dnsmasq section,
+// one dhcp section per pool (named after its interface), odhcpd, static hosts and
+// domain records.
+//
+// A pool serves the subnet of its interface's static IPv4 address. When start or limit
+// are not set they default to the stock .100/150 range, shrunk to fit smaller subnets.
+func buildDhcpPackage(msg *openwrtv1.OpenWrtConfig) (*uci.Package, error) {
+	dhcp := msg.GetDhcp()
+	if dhcp == nil {
+		return nil, nil
+	}
+
+	var sections []*uci.Section
+	if dnsmasq := dhcp.GetDnsmasq(); dnsmasq != nil {
+		section := uci.NewSection("dnsmasq", "dnsmasq")
+		helpers.ApplyOptionsFromMap(section, helpers.ProtoMessageToMap(dnsmasq), nil)
+		sections = append(sections, section)
+	}
+
+	pools := make(map[string]struct{}, len(dhcp.GetPools()))
+	for idx, pool := range dhcp.GetPools() {
+		name := pool.GetInterface()
+		if name == "" {
+			return nil, validationError("dhcp", "pool #%d has no interface", idx+1)
+		}
+		if _, ok := pools[name]; ok {
+			return nil, validationError("dhcp", "duplicate pool for interface %q", name)
+		}
+		pools[name] = struct{}{}
+
+		section, err := buildDhcpPoolSection(pool, findInterface(msg, name))
+		if err != nil {
+			return nil, err
+		}
+		sections = append(sections, section)
+	}
+
+	if odhcpd := dhcp.GetOdhcpd(); odhcpd != nil {
+		section := uci.NewSection("odhcpd", "odhcpd")
+		helpers.ApplyOptionsFromMap(section, helpers.ProtoMessageToMap(odhcpd), nil)
+		sections = append(sections, section)
+	}
+
+	names := make(map[string]struct{})
+	for idx, host := range dhcp.GetHosts() {
+		if host.GetIp() != "" && host.GetIp() != "ignore" && net.ParseIP(host.GetIp()) == nil {
+			return nil, validationError("dhcp", "host %q has invalid ip %q", host.GetName(), host.GetIp())
+		}
+		if len(host.GetMac()) == 0 && host.GetHostid() == "" && host.GetName() == "" {
+			return nil, validationError("dhcp", "host #%d needs a mac, hostid or name", idx+1)
+		}
+		sections = append(sections, newOptionSection(names, "host", host.GetName(), idx, host))
+	}
+	for idx, domain := range dhcp.GetDomains() {
+		if domain.GetName() == "" || net.ParseIP(domain.GetIp()) == nil {
+			return nil, validationError("dhcp", "domain #%d needs a name and a valid ip", idx+1)
+		}
+		sections = append(sections, newOptionSection(names, "domain", domain.GetName(), idx, domain))
+	}
+
+	if len(sections) == 0 {
+		return nil, nil
+	}
+	return &uci.Package{
+		Name:     "dhcp",
+		Sections: sections,
+	}, nil
+}
+
+func buildDhcpPoolSection(pool *openwrtv1.DhcpPool, iface *devicev1.Interface) (*uci.Section, error) {
+	name := pool.GetInterface()
+	for _, check := range []struct{ key, value string }{
+		{"dhcpv4", pool.GetDhcpv4()},
+		{"dhcpv6", pool.GetDhcpv6()},
+		{"ra", pool.GetRa()},
+		{"ndp", pool.GetNdp()},
+	} {
+		if !validDhcpMode(check.key, check.value) {
+			return nil, validationError("dhcp", "pool %q has invalid %s mode %q", name, check.key, check.value)
+		}
+	}
+
+	section := uci.NewSection("dhcp", name)
+	helpers.ApplyOptionsFromMap(section, helpers.ProtoMessageToMap(pool), nil)
+	if pool.GetIgnore() {
+		// the pool only disables the DHCP server on this interface
+		return section, nil
+	}
+
+	if iface == nil {
+		return nil, validationError("dhcp", "pool references unknown interface %q", name)
+	}
+	hosts := staticIPv4Hosts(iface)
+	if hosts == 0 {
+		return nil, validationError("dhcp", "pool interface %q has no static ipv4 address", name)
+	}
+	start, limit := defaultDhcpRange(hosts)
+	if pool.Start != nil {
+		start = pool.GetStart()
+	}
+	if pool.Limit != nil {
+		limit = pool.GetLimit()
+	}
+	if start == 0 || limit == 0 || uint64(start)+uint64(limit)-1 > hosts {
+		return nil, validationError("dhcp", "pool %q range start %d limit %d does not fit the interface subnet", name, start, limit)
+	}
+	helpers.SetUint32Value(section, "start", start)
+	helpers.SetUint32Value(section, "limit", limit)
+	if pool.GetLeasetime() == "" {
+		helpers.SetString(section, "leasetime", "12h")
+	}
+	return section, nil
+}
+
+func validDhcpMode(key, value string) bool {
+	switch value {
+	case "":
+		return true
+	case "server", "disabled":
+		return true
+	case "relay", "hybrid":
+		return key != "dhcpv4"
+	default:
+		return false
+	}
+}
+
+// staticIPv4Hosts returns the number of usable host addresses in the subnet of the first
+// static IPv4 address of iface, or 0 when there is none.
+func staticIPv4Hosts(iface *devicev1.Interface) uint64 {
+	for _, addr := range iface.GetAddresses() {
+		if addr.GetFamily() == "ipv6" || addr.GetProto() == "dhcp" || addr.GetAddress() == "" {
+			continue
+		}
+		mask := addr.GetMask()
+		if mask == 0 {
+			mask = 24
+		}
+		if mask > 30 {
+			return 0
+		}
+		return 1<<(32-mask) - 2
+	}
+	return 0
+}
+
+func defaultDhcpRange(hosts uint64) (start, limit uint32) {
+	if hosts >= defaultDhcpStart+defaultDhcpLimit-1 {
+		return defaultDhcpStart, defaultDhcpLimit
+	}
+	start = uint32(hosts / 2)
+	if start == 0 {
+		start = 1
+	}
+	return start, uint32(hosts) - start + 1
+}
+
+func findInterface(msg *openwrtv1.OpenWrtConfig, name string) *devicev1.Interface {
+	for _, iface := range msg.GetInterfaces() {
+		if iface.GetName() == name {
+			return iface
+		}
+	}
+	return nil
+}
+
+// parseDhcpPackage restores the dhcp block. Pools without an interface option take the
+// section name, like odhcpd and dnsmasq do.
+func parseDhcpPackage(pkg *uci.Package, msg *openwrtv1.OpenWrtConfig) {
+	dhcp := &openwrtv1.DhcpConfig{}
+	for _, section := range pkg.Sections {
+		switch section.Type {
+		case "dnsmasq":
+			dnsmasq := &openwrtv1.DnsmasqSettings{}
+			helpers.ApplyOptionsToMessage(dnsmasq, section, nil)
+			dhcp.Dnsmasq = dnsmasq
+		case "dhcp":
+			pool := &openwrtv1.DhcpPool{}
+			helpers.ApplyOptionsToMessage(pool, section, nil)
+			pool.RaFlags = splitFields(pool.RaFlags)
+			if pool.GetInterface() == "" && !section.Anonymous {
+				pool.Interface = section.Name
+			}
+			dhcp.Pools = append(dhcp.Pools, pool)
+		case "odhcpd":
+			odhcpd := &openwrtv1.OdhcpdSettings{}
+			helpers.ApplyOptionsToMessage(odhcpd, section, nil)
+			dhcp.Odhcpd = odhcpd
+		case "host":
+			host := &openwrtv1.DhcpHost{}
+			helpers.ApplyOptionsToMessage(host, section, nil)
+			host.Mac = splitFields(host.Mac)
+			dhcp.Hosts = append(dhcp.Hosts, host)
+		case "domain":
+			domain := &openwrtv1.DhcpDomain{}
+			helpers.ApplyOptionsToMessage(domain, section, nil)
+			dhcp.Domains = append(dhcp.Domains, domain)
+		}
+	}
+	if proto.Size(dhcp) > 0 {
+		msg.Dhcp = dhcp
+	}
+}
@@ -0,0 +1,238 @@
+package openwrt
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+
+	openwrtv1 "github.com/honeybbq/netjson/gen/go/netjson/openwrt/v1"
+
+	helpers "github.com/honeybbq/netjsonconfig/domain/utils"
+	"github.com/honeybbq/netjsonconfig/pkg/ast/uci"
+)
+
+// buildFirewallPackage renders /etc/config/firewall. The package is only generated when
+// the config carries a firewall block; interface zone options alone leave it untouched.
+//
+// Zone networks are the union of the zone's explicit network list and every interface
+// whose firewall_zone names the zone, so interfaces only need to be tagged once.
+func buildFirewallPackage(msg *openwrtv1.OpenWrtConfig) (*uci.Package, error) {
+	fw := msg.GetFirewall()
+	if fw == nil {
+		return nil, nil
+	}
+	if err := validateFirewall(msg); err != nil {
+		return nil, err
+	}
+
+	names := make(map[string]struct{})
+	var sections []*uci.Section
+	if defaults := fw.GetDefaults(); defaults != nil {
+		section := uci.NewSection("defaults", "defaults")
+		helpers.ApplyOptionsFromMap(section, helpers.ProtoMessageToMap(defaults), nil)
+		names[section.Name] = struct{}{}
+		sections = append(sections, section)
+	}
+
+	for idx, zone := range fw.GetZones() {
+		section := newNamedSection(names, "zone", zone.GetName(), idx)
+		helpers.ApplyOptionsFromMap(section, helpers.ProtoMessageToMap(zone), map[string]struct{}{"network": {}})
+		helpers.SetList(section, "network", zoneNetworks(zone, msg))
+		sections = append(sections, section)
+	}
+	for idx, fwd := range fw.GetForwardings() {
+		preferred := fwd.GetName()
+		if preferred == "" {
+			preferred = fwd.GetSrc() + "_" + fwd.GetDest()
+		}
+		sections = append(sections, newOptionSection(names, "forwarding", preferred, idx, fwd))
+	}
+	for idx, rule := range fw.GetRules() {
+		sections = append(sections, newOptionSection(names, "rule", rule.GetName(), idx, rule))
+	}
+	for idx, redirect := range fw.GetRedirects() {
+		sections = append(sections, newOptionSection(names, "redirect", redirect.GetName(), idx, redirect))
+	}
+	for idx, nat := range fw.GetNat() {
+		sections = append(sections, newOptionSection(names, "nat", nat.GetName(), idx, nat))
+	}
+	for idx, include := range fw.GetIncludes() {
+		// include sections have no name option; the name only selects the section name,
+		// so unnamed includes stay anonymous like the stock /etc/config/firewall
+		var section *uci.Section
+		if include.GetName() == "" {
+			// same naming the parser gives anonymous sections
+			name := fmt.Sprintf("include_%d", idx)
+			names[name] = struct{}{}
+			section = uci.NewSection("include", name)
+			section.Anonymous = true
+		} else {
+			section = newNamedSection(names, "include", include.GetName(), idx)
+		}
+		helpers.ApplyOptionsFromMap(section, helpers.ProtoMessageToMap(include), map[string]struct{}{"name": {}})
+		sections = append(sections, section)
+	}
+
+	if len(sections) == 0 {
+		return nil, nil
+	}
+	return &uci.Package{
+		Name:     "firewall",
+		Sections: sections,
+	}, nil
+}
+
+func zoneNetworks(zone *openwrtv1.FirewallZone, msg *openwrtv1.OpenWrtConfig) []string {
+	networks := slices.Clone(zone.GetNetwork())
+	for _, iface := range msg.GetInterfaces() {
+		if iface.GetFirewallZone() == zone.GetName() && iface.GetName() != "" && !slices.Contains(networks, iface.GetName()) {
+			networks = append(networks, iface.GetName())
+		}
+	}
+	return networks
+}
+
+// validateFirewall checks that zones are named uniquely and that interfaces, forwardings,
+// rules and redirects only reference declared zones ("*" matches any zone).
+func validateFirewall(msg *openwrtv1.OpenWrtConfig) error {
+	fw := msg.GetFirewall()
+	zones := make(map[string]struct{}, len(fw.GetZones()))
+	for idx, zone := range fw.GetZones() {
+		if zone.GetName() == "" {
+			return validationError("firewall", "zone #%d has no name", idx+1)
+		}
+		if _, ok := zones[zone.GetName()]; ok {
+			return validationError("firewall", "duplicate zone %q", zone.GetName())
+		}
+		zones[zone.GetName()] = struct{}{}
+	}
+
+	check := func(owner, zone string, required bool) error {
+		if zone == "" {
+			if required {
+				return validationError("firewall", "%s requires a zone", owner)
+			}
+			return nil
+		}
+		if _, ok := zones[zone]; !ok && zone != "*" {
+			return validationError("firewall", "%s references undefined zone %q", owner, zone)
+		}
+		return nil
+	}
+
+	for _, iface := range msg.GetInterfaces() {
+		if err := check(fmt.Sprintf("interface %q", iface.GetName()), iface.GetFirewallZone(), false); err != nil {
+			return err
+		}
+	}
+	for idx, fwd := range fw.GetForwardings() {
+		owner := fmt.Sprintf("forwarding #%d", idx+1)
+		if err := check(owner, fwd.GetSrc(), true); err != nil {
+			return err
+		}
+		if err := check(owner, fwd.GetDest(), true); err != nil {
+			return err
+		}
+	}
+	for idx, rule := range fw.GetRules() {
+		owner := firewallOwner("rule", rule.GetName(), idx)
+		if err := check(owner, rule.GetSrc(), false); err != nil {
+			return err
+		}
+		if err := check(owner, rule.GetDest(), false); err != nil {
+			return err
+		}
+	}
+	for idx, redirect := range fw.GetRedirects() {
+		owner := firewallOwner("redirect", redirect.GetName(), idx)
+		if err := check(owner, redirect.GetSrc(), false); err != nil {
+			return err
+		}
+		if err := check(owner, redirect.GetDest(), false); err != nil {
+			return err
+		}
+	}
+	for idx, nat := range fw.GetNat() {
+		if err := check(firewallOwner("nat", nat.GetName(), idx), nat.GetSrc(), false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func firewallOwner(typ, name string, idx int) string {
+	if name != "" {
+		return fmt.Sprintf("%s %q", typ, name)
+	}
+	return fmt.Sprintf("%s #%d", typ, idx+1)
+}
+
+// parseFirewallPackage restores the firewall block. Space separated values of list
+// fields (option network 'lan wan6', option proto 'tcp udp') are split into items.
+func parseFirewallPackage(pkg *uci.Package, msg *openwrtv1.OpenWrtConfig) {
+	fw := &openwrtv1.FirewallConfig{}
+	for _, section := range pkg.Sections {
+		switch section.Type {
+		case "defaults":
+			defaults := &openwrtv1.FirewallDefaults{}
+			helpers.ApplyOptionsToMessage(defaults, section, nil)
+			fw.Defaults = defaults
+		case "zone":
+			zone := &openwrtv1.FirewallZone{}
+			helpers.ApplyOptionsToMessage(zone, section, nil)
+			zone.Network = splitFields(zone.Network)
+			zone.Device = splitFields(zone.Device)
+			zone.Subnet = splitFields(zone.Subnet)
+			if zone.GetName() == "" {
+				zone.Name = firewallSectionName(section, "zone_")
+			}
+			fw.Zones = append(fw.Zones, zone)
+		case "forwarding":
+			fwd := &openwrtv1.FirewallForwarding{}
+			helpers.ApplyOptionsToMessage(fwd, section, nil)
+			fw.Forwardings = append(fw.Forwardings, fwd)
+		case "rule":
+			rule := &openwrtv1.FirewallRule{}
+			helpers.ApplyOptionsToMessage(rule, section, nil)
+			rule.Proto = splitFields(rule.Proto)
+			rule.IcmpType = splitFields(rule.IcmpType)
+			fw.Rules = append(fw.Rules, rule)
+		case "redirect":
+			redirect := &openwrtv1.FirewallRedirect{}
+			helpers.ApplyOptionsToMessage(redirect, section, nil)
+			redirect.Proto = splitFields(redirect.Proto)
+			fw.Redirects = append(fw.Redirects, redirect)
+		case "nat":
+			nat := &openwrtv1.FirewallNat{}
+			helpers.ApplyOptionsToMessage(nat, section, nil)
+			nat.Proto = splitFields(nat.Proto)
+			fw.Nat = append(fw.Nat, nat)
+		case "include":
+			include := &openwrtv1.FirewallInclude{}
+			helpers.ApplyOptionsToMessage(include, section, map[string]struct{}{"name": {}})
+			include.Name = firewallSectionName(section, "include_")
+			fw.Includes = append(fw.Includes, include)
+		}
+	}
+	if proto.Size(fw) > 0 {
+		msg.Firewall = fw
+	}
+}
+
+// firewallSectionName recovers the name encoded in a section name; anonymous sections yield "".
+func firewallSectionName(section *uci.Section, prefix string) string {
+	if section.Anonymous {
+		return ""
+	}
+	return strings.TrimPrefix(section.Name, prefix)
+}
+
+func splitFields(values []string) []string {
+	var fields []string
+	for _, value := range values {
+		fields = append(fields, strings.Fields(value)...)
+	}
+	return fields
+}
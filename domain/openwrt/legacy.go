@@ -0,0 +1,184 @@
+package openwrt
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	devicev1 "github.com/honeybbq/netjson/gen/go/netjson/device/v1"
+	openwrtv1 "github.com/honeybbq/netjson/gen/go/netjson/openwrt/v1"
+
+	helpers "github.com/honeybbq/netjsonconfig/domain/utils"
+	"github.com/honeybbq/netjsonconfig/pkg/ast/uci"
+)
+
+// buildLegacyInterfaceSections renders non-wireless interfaces with legacy syntax.
+//
+// The DSA interface section is reused and rewritten: device becomes ifname and
+// bridges carry type bridge, their members and L2 options inline. Each
+// vlan_filtering entry becomes a bridge named <iface>_<vid> whose members are
+// eth0.<vid> for tagged ports and the bare port for untagged ones; untagged
+// ports are removed from the parent bridge since a port can only join one bridge.
+// Additional IPv4 addresses become alias interfaces (see buildLegacyAliasSections).
+func buildLegacyInterfaceSections(msg *openwrtv1.OpenWrtConfig, vnis map[string]uint32) []*uci.Section {
+	var sections []*uci.Section
+	for _, iface := range msg.GetInterfaces() {
+		if iface.GetWireless() != nil {
+			continue
+		}
+		// netifd device sections predate DSA; virtual devices keep them in legacy syntax
+		if isVirtualDevice(iface) {
+			sections = append(sections, buildVirtualDeviceSection(iface))
+		}
+		section := buildInterfaceSection(iface, msg, vnis)
+		if section == nil {
+			continue
+		}
+
+		var aliases []*uci.Section
+		switch {
+		case strings.EqualFold(iface.GetType(), "bridge"):
+			section.Delete("device")
+			helpers.SetString(section, "type", "bridge")
+			untagged := untaggedVlanPorts(iface)
+			var members []string
+			for _, member := range iface.GetBridgeMembers() {
+				if _, ok := untagged[member]; !ok {
+					members = append(members, member)
+				}
+			}
+			helpers.SetString(section, "ifname", strings.Join(members, " "))
+			applyBridgeOptions(section, iface)
+			aliases = buildLegacyAliasSections(section, iface)
+		case strings.EqualFold(iface.GetType(), "wireguard"), isVxlanInterface(iface),
+			isTunnelProto(iface.GetProto()), isModemProto(iface.GetProto()):
+		default:
+			device, _ := section.Option("device")
+			section.Delete("device")
+			ifnames := section.List("ifname")
+			section.Delete("ifname")
+			if len(ifnames) == 0 {
+				ifnames = []string{device}
+			}
+			helpers.SetString(section, "ifname", strings.Join(ifnames, " "))
+			aliases = buildLegacyAliasSections(section, iface)
+		}
+
+		sections = append(sections, section)
+		sections = append(sections, aliases...)
+		sections = append(sections, buildLegacyVlanSections(iface)...)
+	}
+	return sections
+}
+
+// buildLegacyVlanSections converts vlan_filtering into 802.1q sub-interface bridges.
+func buildLegacyVlanSections(iface *devicev1.Interface) []*uci.Section {
+	if !strings.EqualFold(iface.GetType(), "bridge") {
+		return nil
+	}
+
+	var sections []*uci.Section
+	for _, vlan := range iface.GetVlanFiltering() {
+		if vlan == nil || vlan.GetVlan() == 0 {
+			continue
+		}
+		var members []string
+		for _, port := range vlan.GetPorts() {
+			if port == nil || port.GetIfname() == "" {
+				continue
+			}
+			if port.GetTagging() == "u" {
+				members = append(members, port.GetIfname())
+			} else {
+				members = append(members, fmt.Sprintf("%s.%d", port.GetIfname(), vlan.GetVlan()))
+			}
+		}
+		if len(members) == 0 {
+			continue
+		}
+
+		section := uci.NewSection("interface", fmt.Sprintf("%s_%d", iface.GetName(), vlan.GetVlan()))
+		helpers.SetString(section, "type", "bridge")
+		helpers.SetString(section, "ifname", strings.Join(members, " "))
+		helpers.SetString(section, "proto", "none")
+		sections = append(sections, section)
+	}
+	return sections
+}
+
+func untaggedVlanPorts(iface *devicev1.Interface) map[string]struct{} {
+	ports := make(map[string]struct{})
+	for _, vlan := range iface.GetVlanFiltering() {
+		for _, port := range vlan.GetPorts() {
+			if port.GetTagging() == "u" {
+				ports[port.GetIfname()] = struct{}{}
+			}
+		}
+	}
+	return ports
+}
+
+// parseLegacyBridge restores a bridge declared with type bridge on the interface.
+// L2 options live on the interface section; folded VLAN bridges contribute
+// their untagged ports back to the member list.
+func parseLegacyBridge(section *uci.Section, iface *devicev1.Interface, index *networkIndex) {
+	iface.Type = "bridge"
+	parseBridgeDevice(section, iface)
+
+	device := "br-" + section.Name
+	iface.BridgeMembers = splitValues(section, "ifname")
+	iface.VlanFiltering = index.vlans[device]
+	for _, vlan := range iface.GetVlanFiltering() {
+		for _, port := range vlan.GetPorts() {
+			if port.GetTagging() == "u" && !slices.Contains(iface.BridgeMembers, port.GetIfname()) {
+				iface.BridgeMembers = append(iface.BridgeMembers, port.GetIfname())
+			}
+		}
+	}
+}
+
+// indexLegacyVlans finds the "<iface>_<vid>" bridges produced by
+// buildLegacyVlanSections and converts them back into VlanFilter entries keyed
+// by the parent bridge device (br-<iface>). Only bridges that carry nothing but
+// type, ifname and proto none are folded; anything else stays an interface.
+func indexLegacyVlans(pkg *uci.Package, index *networkIndex) {
+	parents := make(map[string]struct{})
+	for _, section := range pkg.Sections {
+		if section.Type == "interface" && helpers.GetString(section, "type") == "bridge" {
+			parents[section.Name] = struct{}{}
+		}
+	}
+
+	for _, section := range pkg.Sections {
+		if section.Type != "interface" || len(section.Entries) != 3 ||
+			helpers.GetString(section, "type") != "bridge" || helpers.GetString(section, "proto") != "none" {
+			continue
+		}
+		idx := strings.LastIndex(section.Name, "_")
+		if idx <= 0 {
+			continue
+		}
+		parent := section.Name[:idx]
+		vid, err := strconv.ParseUint(section.Name[idx+1:], 10, 32)
+		if _, ok := parents[parent]; !ok || err != nil || vid == 0 {
+			continue
+		}
+
+		vlan := &devicev1.VlanFilter{Vlan: uint32(vid)}
+		suffix := fmt.Sprintf(".%d", vid)
+		for _, member := range splitValues(section, "ifname") {
+			if ifname, ok := strings.CutSuffix(member, suffix); ok {
+				vlan.Ports = append(vlan.Ports, &devicev1.VlanPort{Ifname: ifname, Tagging: "t"})
+			} else {
+				vlan.Ports = append(vlan.Ports, &devicev1.VlanPort{Ifname: member, Tagging: "u"})
+			}
+		}
+		if len(vlan.Ports) == 0 {
+			continue
+		}
+		device := "br-" + parent
+		index.vlans[device] = append(index.vlans[device], vlan)
+		index.legacyVlanInterfaces[section.Name] = struct{}{}
+	}
+}
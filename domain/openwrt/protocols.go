@@ -0,0 +1,222 @@
+package openwrt
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+
+	devicev1 "github.com/honeybbq/netjson/gen/go/netjson/device/v1"
+	openwrtv1 "github.com/honeybbq/netjson/gen/go/netjson/openwrt/v1"
+
+	helpers "github.com/honeybbq/netjsonconfig/domain/utils"
+	"github.com/honeybbq/netjsonconfig/pkg/ast/uci"
+	"github.com/honeybbq/netjsonconfig/pkg/nxerrors"
+)
+
+// Protocols with typed settings. Their options are written by applyProtocolSettings
+// instead of the generic address handling.
+const (
+	protoPPPoE        = "pppoe"
+	proto6in4         = "6in4"
+	protoQMI          = "qmi"
+	protoModemManager = "modemmanager"
+)
+
+var greProtocols = map[string]bool{
+	"gre":      false,
+	"gretap":   false,
+	"grev6":    true,
+	"grev6tap": true,
+}
+
+func isGreProto(protocol string) bool {
+	_, ok := greProtocols[protocol]
+	return ok
+}
+
+// isTunnelProto reports protocols whose device is created by netifd itself.
+func isTunnelProto(protocol string) bool {
+	return protocol == proto6in4 || isGreProto(protocol)
+}
+
+func isModemProto(protocol string) bool {
+	return protocol == protoQMI || protocol == protoModemManager
+}
+
+// hasProtocolSettings reports protocols handled by applyProtocolSettings.
+func hasProtocolSettings(protocol string) bool {
+	return protocol == protoPPPoE || isTunnelProto(protocol) || isModemProto(protocol)
+}
+
+// validateInterfaceProtocols checks the typed settings of every interface against its proto.
+func validateInterfaceProtocols(msg *openwrtv1.OpenWrtConfig) error {
+	for _, iface := range msg.GetInterfaces() {
+		if err := validateProtocolSettings(iface); err != nil {
+			return nxerrors.New(nxerrors.KindValidation, fmt.Errorf("interface %q: %w", iface.GetName(), err))
+		}
+	}
+	return nil
+}
+
+func validateProtocolSettings(iface *devicev1.Interface) error {
+	protocol := iface.GetProto()
+	switch {
+	case protocol == protoPPPoE:
+		pppoe := iface.GetPppoe()
+		if (pppoe.GetUsername() == "") != (pppoe.GetPassword() == "") {
+			return fmt.Errorf("pppoe username and password must be set together")
+		}
+	case protocol == proto6in4:
+		tunnel := iface.GetTunnel6In4()
+		if ip := net.ParseIP(tunnel.GetPeeraddr()); ip == nil || ip.To4() == nil {
+			return fmt.Errorf("6in4 peeraddr %q is not an IPv4 address", tunnel.GetPeeraddr())
+		}
+		if addr := tunnel.GetIpaddr(); addr != "" && net.ParseIP(addr).To4() == nil {
+			return fmt.Errorf("6in4 ipaddr %q is not an IPv4 address", addr)
+		}
+		for _, prefix := range append([]string{tunnel.GetIp6Addr()}, tunnel.GetIp6Prefix()...) {
+			if prefix == "" {
+				continue
+			}
+			if ip, _, err := net.ParseCIDR(prefix); err != nil || ip.To4() != nil {
+				return fmt.Errorf("6in4 address %q is not an IPv6 prefix", prefix)
+			}
+		}
+	case isGreProto(protocol):
+		gre := iface.GetGre()
+		ipv6 := greProtocols[protocol]
+		addrs := []string{gre.GetPeeraddr()}
+		if gre.GetIpaddr() != "" {
+			addrs = append(addrs, gre.GetIpaddr())
+		}
+		for _, addr := range addrs {
+			ip := net.ParseIP(addr)
+			if ip == nil || (ip.To4() == nil) != ipv6 {
+				return fmt.Errorf("%s address %q does not match the protocol family", protocol, addr)
+			}
+		}
+	case isModemProto(protocol):
+		modem := iface.GetModem()
+		if modem.GetDevice() == "" {
+			return fmt.Errorf("%s requires the modem device", protocol)
+		}
+		switch modem.GetAuth() {
+		case "", "none", "pap", "chap", "both":
+		default:
+			return fmt.Errorf("invalid %s auth %q (want none, pap, chap or both)", protocol, modem.GetAuth())
+		}
+		switch modem.GetPdptype() {
+		case "", "ipv4", "ipv6", "ipv4v6":
+		default:
+			return fmt.Errorf("invalid %s pdptype %q (want ipv4, ipv6 or ipv4v6)", protocol, modem.GetPdptype())
+		}
+		if pin := modem.GetPincode(); pin != "" && (len(pin) < 4 || len(pin) > 8 || strings.Trim(pin, "0123456789") != "") {
+			return fmt.Errorf("%s pincode must be 4 to 8 digits", protocol)
+		}
+	}
+	return nil
+}
+
+// applyProtocolSettings writes the typed settings of pppoe, 6in4, gre and modem interfaces.
+func applyProtocolSettings(section *uci.Section, iface *devicev1.Interface) {
+	protocol := iface.GetProto()
+	switch {
+	case protocol == protoPPPoE:
+		helpers.ApplyOptionsFromMap(section, helpers.ProtoMessageToMap(iface.GetPppoe()), nil)
+	case protocol == proto6in4:
+		helpers.ApplyOptionsFromMap(section, helpers.ProtoMessageToMap(iface.GetTunnel6In4()), nil)
+	case isGreProto(protocol):
+		gre := iface.GetGre()
+		helpers.ApplyOptionsFromMap(section, helpers.ProtoMessageToMap(gre), map[string]struct{}{
+			"peeraddr": {},
+			"ipaddr":   {},
+		})
+		// grev6 and grev6tap take the endpoints as peer6addr/ip6addr
+		peer, local := "peeraddr", "ipaddr"
+		if greProtocols[protocol] {
+			peer, local = "peer6addr", "ip6addr"
+		}
+		helpers.SetString(section, peer, gre.GetPeeraddr())
+		helpers.SetString(section, local, gre.GetIpaddr())
+	case protocol == protoQMI:
+		helpers.ApplyOptionsFromMap(section, helpers.ProtoMessageToMap(iface.GetModem()), nil)
+	case protocol == protoModemManager:
+		modem := iface.GetModem()
+		helpers.ApplyOptionsFromMap(section, helpers.ProtoMessageToMap(modem), map[string]struct{}{
+			"auth":        {},
+			"pdptype":     {},
+			"delay":       {},
+			"modes":       {},
+			"profile":     {},
+			"autoconnect": {},
+		})
+		helpers.SetString(section, "iptype", modem.GetPdptype())
+		helpers.SetList(section, "allowedauth", modemManagerAuth(modem.GetAuth()))
+	}
+}
+
+// modemManagerAuth maps the qmi style auth value onto ModemManager's allowedauth list.
+func modemManagerAuth(auth string) []string {
+	switch auth {
+	case "":
+		return nil
+	case "both":
+		return []string{"pap", "chap"}
+	default:
+		return []string{auth}
+	}
+}
+
+// parseProtocolSettings restores interfaces handled by applyProtocolSettings.
+func parseProtocolSettings(section *uci.Section, iface *devicev1.Interface, syntax Syntax) {
+	protocol := helpers.GetString(section, "proto")
+	iface.Type = "other"
+	switch {
+	case protocol == protoPPPoE:
+		iface.Type = "ethernet"
+		device := helpers.GetString(section, "device")
+		if syntax == SyntaxLegacy {
+			device = helpers.GetString(section, "ifname")
+		}
+		if device != section.Name {
+			iface.Device = device
+		}
+		pppoe := &devicev1.PppoeSettings{}
+		helpers.ApplyOptionsToMessage(pppoe, section, nil)
+		if proto.Size(pppoe) > 0 {
+			iface.Pppoe = pppoe
+		}
+	case protocol == proto6in4:
+		tunnel := &devicev1.Tunnel6In4Settings{}
+		helpers.ApplyOptionsToMessage(tunnel, section, nil)
+		iface.Tunnel6In4 = tunnel
+	case isGreProto(protocol):
+		gre := &devicev1.GreSettings{}
+		helpers.ApplyOptionsToMessage(gre, section, map[string]struct{}{"ipaddr": {}})
+		if greProtocols[protocol] {
+			gre.Peeraddr = helpers.GetString(section, "peer6addr")
+			gre.Ipaddr = helpers.GetString(section, "ip6addr")
+		} else {
+			gre.Ipaddr = helpers.GetString(section, "ipaddr")
+		}
+		iface.Gre = gre
+	case protocol == protoQMI:
+		modem := &devicev1.ModemSettings{}
+		helpers.ApplyOptionsToMessage(modem, section, nil)
+		iface.Modem = modem
+	case protocol == protoModemManager:
+		modem := &devicev1.ModemSettings{}
+		helpers.ApplyOptionsToMessage(modem, section, map[string]struct{}{"auth": {}, "pdptype": {}})
+		modem.Pdptype = helpers.GetString(section, "iptype")
+		switch auth := splitValues(section, "allowedauth"); {
+		case len(auth) == 2 && auth[0] == "pap" && auth[1] == "chap":
+			modem.Auth = "both"
+		case len(auth) == 1:
+			modem.Auth = auth[0]
+		}
+		iface.Modem = modem
+	}
+	iface.Mtu = helpers.GetUint32Ptr(section, "mtu")
+}
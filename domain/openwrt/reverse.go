@@ -0,0 +1,759 @@
+package openwrt
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+
+	commonv1 "github.com/honeybbq/netjson/gen/go/netjson/common/v1"
+	devicev1 "github.com/honeybbq/netjson/gen/go/netjson/device/v1"
+	openvpnv1 "github.com/honeybbq/netjson/gen/go/netjson/openvpn/v1"
+	openwrtv1 "github.com/honeybbq/netjson/gen/go/netjson/openwrt/v1"
+	zerotierv1 "github.com/honeybbq/netjson/gen/go/netjson/zerotier/v1"
+
+	openvpndomain "github.com/honeybbq/netjsonconfig/domain/openvpn"
+	helpers "github.com/honeybbq/netjsonconfig/domain/utils"
+	"github.com/honeybbq/netjsonconfig/pkg/ast/uci"
+)
+
+// parseSystemPackage restores general, ntp and led settings from the system package.
+func parseSystemPackage(pkg *uci.Package, msg *openwrtv1.OpenWrtConfig) {
+	for _, section := range pkg.Sections {
+		switch section.Type {
+		case "system":
+			general := ensureGeneral(msg)
+			helpers.ApplyOptionsToMessage(general, section, map[string]struct{}{
+				"ula_prefix": {},
+				"globals_id": {},
+			})
+			parseTimezone(general)
+		case "timeserver":
+			ntp := &openwrtv1.NtpSettings{}
+			helpers.ApplyOptionsToMessage(ntp, section, nil)
+			msg.Ntp = ntp
+		case "led":
+			led := &openwrtv1.Led{}
+			helpers.ApplyOptionsToMessage(led, section, nil)
+			if led.GetName() == "" && !section.Anonymous {
+				led.Name = strings.TrimPrefix(section.Name, "led_")
+			}
+			msg.Leds = append(msg.Leds, led)
+		}
+	}
+}
+
+func ensureGeneral(msg *openwrtv1.OpenWrtConfig) *devicev1.General {
+	if msg.General == nil {
+		msg.General = &devicev1.General{}
+	}
+	return msg.General
+}
+
+// parseNetworkPackage restores interfaces, routes, rules, wireguard peers and switches.
+func parseNetworkPackage(pkg *uci.Package, msg *openwrtv1.OpenWrtConfig, syntax Syntax) {
+	index := indexNetworkDevices(pkg)
+	index.syntax = syntax
+	if syntax == SyntaxLegacy {
+		indexLegacyVlans(pkg, index)
+	}
+
+	switches := make(map[string]*openwrtv1.SwitchConfig)
+	aliases := make(map[string][]*uci.Section)
+	var aliasParents []string
+	for _, section := range pkg.Sections {
+		switch {
+		case section.Type == "globals":
+			general := ensureGeneral(msg)
+			general.UlaPrefix = helpers.GetString(section, "ula_prefix")
+			if !section.Anonymous && section.Name != "" && section.Name != "globals" {
+				general.GlobalsId = section.Name
+			}
+		case section.Type == "device" && isVirtualDeviceSection(section):
+			if _, ok := index.deviceRefs[helpers.GetString(section, "name")]; !ok {
+				msg.Interfaces = append(msg.Interfaces, parseDeviceOnly(section))
+			}
+		case section.Type == "interface":
+			if index.isVlanInterface(section) {
+				continue
+			}
+			if parent, ok := legacyAliasParent(section); ok && syntax == SyntaxLegacy {
+				if _, ok := aliases[parent]; !ok {
+					aliasParents = append(aliasParents, parent)
+				}
+				aliases[parent] = append(aliases[parent], section)
+				continue
+			}
+			if iface := parseInterfaceSection(section, index); iface != nil {
+				msg.Interfaces = append(msg.Interfaces, iface)
+			}
+		case section.Type == "route" || section.Type == "route6":
+			msg.Routes = append(msg.Routes, parseRouteSection(section))
+		case section.Type == "rule" || section.Type == "rule6":
+			msg.IpRules = append(msg.IpRules, parseRuleSection(section))
+		case strings.HasPrefix(section.Type, "wireguard_"):
+			msg.WireguardPeers = append(msg.WireguardPeers, parseWireguardPeerSection(section))
+		case section.Type == "switch":
+			sw := parseSwitchSection(section)
+			switches[sw.GetName()] = sw
+			msg.Switches = append(msg.Switches, sw)
+		case section.Type == "switch_vlan":
+			vlan := &openwrtv1.SwitchVlan{
+				Device: helpers.GetString(section, "device"),
+				VlanId: helpers.GetUint32Value(section, "vlan"),
+				Ports:  helpers.GetString(section, "ports"),
+			}
+			sw, ok := switches[vlan.GetDevice()]
+			if !ok {
+				sw = &openwrtv1.SwitchConfig{Name: vlan.GetDevice()}
+				switches[sw.GetName()] = sw
+				msg.Switches = append(msg.Switches, sw)
+			}
+			sw.Vlans = append(sw.Vlans, vlan)
+		}
+	}
+
+	// alias interfaces fold their address into the parent; orphans stay interfaces
+	for _, parent := range aliasParents {
+		iface := findInterface(msg, parent)
+		for _, section := range aliases[parent] {
+			if iface != nil {
+				iface.Addresses = append(iface.Addresses, parseInterfaceAddresses(section)...)
+			} else if orphan := parseInterfaceSection(section, index); orphan != nil {
+				msg.Interfaces = append(msg.Interfaces, orphan)
+			}
+		}
+	}
+}
+
+// networkIndex holds the device-level sections interfaces refer to.
+// It is built before the interface pass so that section order does not matter.
+type networkIndex struct {
+	// bridges maps a bridge device name (br-xxx) to its device section.
+	bridges map[string]*uci.Section
+	// vlans maps a bridge device name to the VLANs defined by its bridge-vlan sections.
+	vlans map[string][]*devicev1.VlanFilter
+	// vlanInterfaces maps the synthetic "<iface>_<vid>" interface name to its device (br-xxx.vid).
+	vlanInterfaces map[string]string
+	// devices maps the name of an 8021q/8021ad, macvlan or veth device to its section.
+	devices map[string]*uci.Section
+	// deviceRefs holds the device names interface sections refer to.
+	deviceRefs map[string]struct{}
+	// legacyVlanInterfaces holds the legacy "<iface>_<vid>" bridges folded into vlan_filtering.
+	legacyVlanInterfaces map[string]struct{}
+	syntax               Syntax
+}
+
+func indexNetworkDevices(pkg *uci.Package) *networkIndex {
+	index := &networkIndex{
+		bridges:        make(map[string]*uci.Section),
+		vlans:          make(map[string][]*devicev1.VlanFilter),
+		vlanInterfaces: make(map[string]string),
+		devices:        make(map[string]*uci.Section),
+		deviceRefs:     make(map[string]struct{}),
+
+		legacyVlanInterfaces: make(map[string]struct{}),
+	}
+	for _, section := range pkg.Sections {
+		switch section.Type {
+		case "device":
+			if isVirtualDeviceSection(section) {
+				index.devices[helpers.GetString(section, "name")] = section
+				continue
+			}
+			if helpers.GetString(section, "type") != "bridge" {
+				continue
+			}
+			if name := helpers.GetString(section, "name"); name != "" {
+				index.bridges[name] = section
+			}
+		case "bridge-vlan":
+			device := helpers.GetString(section, "device")
+			vlan := parseBridgeVlanSection(section)
+			if device == "" || vlan == nil {
+				continue
+			}
+			index.vlans[device] = append(index.vlans[device], vlan)
+			// buildBridgeVlanSections emits "<iface>_<vid>" with device br-<iface>.<vid>
+			if base, ok := strings.CutPrefix(device, "br-"); ok {
+				name := fmt.Sprintf("%s_%d", base, vlan.GetVlan())
+				index.vlanInterfaces[name] = fmt.Sprintf("%s.%d", device, vlan.GetVlan())
+			}
+		case "interface":
+			// legacy interfaces name their device in ifname; bridge members are only ports
+			refs := []string{helpers.GetString(section, "device")}
+			if helpers.GetString(section, "type") != "bridge" {
+				refs = append(refs, helpers.GetString(section, "ifname"))
+			}
+			for _, ref := range refs {
+				index.deviceRefs[ref] = struct{}{}
+			}
+		}
+	}
+	return index
+}
+
+// isVlanInterface reports whether section is the proto=none interface generated
+// alongside a bridge-vlan section. Such interfaces are implied by vlan_filtering
+// and would otherwise be duplicated on the next render. Interfaces carrying any
+// additional settings are kept as regular interfaces.
+func (n *networkIndex) isVlanInterface(section *uci.Section) bool {
+	if _, ok := n.legacyVlanInterfaces[section.Name]; ok {
+		return true
+	}
+	device, ok := n.vlanInterfaces[section.Name]
+	if !ok || len(section.Entries) != 2 {
+		return false
+	}
+	return helpers.GetString(section, "device") == device && helpers.GetString(section, "proto") == "none"
+}
+
+// parseBridgeVlanSection converts a bridge-vlan section into a VlanFilter.
+// Ports use the "ifname[:flags]" notation, where flags combine u/t and the
+// primary VID marker "*".
+func parseBridgeVlanSection(section *uci.Section) *devicev1.VlanFilter {
+	vid := helpers.GetUint32Value(section, "vlan")
+	if vid == 0 {
+		return nil
+	}
+	vlan := &devicev1.VlanFilter{Vlan: vid}
+	for _, value := range helpers.GetValues(section, "ports") {
+		ifname, flags, _ := strings.Cut(value, ":")
+		if ifname == "" {
+			continue
+		}
+		port := &devicev1.VlanPort{
+			Ifname:     ifname,
+			PrimaryVid: strings.Contains(flags, "*"),
+		}
+		switch {
+		case strings.Contains(flags, "t"):
+			port.Tagging = "t"
+		case strings.Contains(flags, "u"):
+			port.Tagging = "u"
+		}
+		vlan.Ports = append(vlan.Ports, port)
+	}
+	return vlan
+}
+
+func parseInterfaceSection(section *uci.Section, index *networkIndex) *devicev1.Interface {
+	if section.Name == "" {
+		return nil
+	}
+	iface := &devicev1.Interface{Name: section.Name}
+	protocol := helpers.GetString(section, "proto")
+	device := helpers.GetString(section, "device")
+	isWireguard := protocol == "wireguard" || helpers.GetString(section, "type") == "wireguard"
+	ref := device
+	if index.syntax == SyntaxLegacy {
+		ref = helpers.GetString(section, "ifname")
+	}
+	virtual := index.devices[ref]
+
+	switch {
+	case isWireguard:
+		iface.Type = "wireguard"
+		iface.Device = device
+		parseWireguardInterface(section, iface)
+	case isVxlanProto(protocol):
+		parseVxlanInterface(section, iface)
+	case hasProtocolSettings(protocol):
+		parseProtocolSettings(section, iface, index.syntax)
+	case virtual != nil:
+		parseVirtualDevice(virtual, iface)
+	case index.bridges[device] != nil:
+		iface.Type = "bridge"
+		parseBridgeDevice(index.bridges[device], iface)
+		iface.VlanFiltering = index.vlans[device]
+	case helpers.GetString(section, "type") == "bridge":
+		// legacy: type bridge + ifname on the interface itself
+		parseLegacyBridge(section, iface, index)
+	case index.syntax == SyntaxLegacy:
+		iface.Type = "ethernet"
+		ifnames := splitValues(section, "ifname")
+		if len(ifnames) == 1 {
+			if ifnames[0] != section.Name {
+				iface.Device = ifnames[0]
+			}
+		} else {
+			iface.Ifname = ifnames
+		}
+		iface.Mtu = helpers.GetUint32Ptr(section, "mtu")
+	default:
+		iface.Type = "ethernet"
+		if device != "" && device != section.Name {
+			iface.Device = device
+		}
+		iface.Mtu = helpers.GetUint32Ptr(section, "mtu")
+		iface.Ifname = helpers.GetList(section, "ifname")
+	}
+
+	isTunnel := isWireguard || iface.GetType() == "vxlan"
+	if !isTunnel {
+		iface.Proto = protocol
+	}
+	iface.Ip4Table = helpers.GetStringPtr(section, "ip4table")
+	iface.Ip6Table = helpers.GetStringPtr(section, "ip6table")
+	iface.Ip6Hint = helpers.GetStringPtr(section, "ip6hint")
+	iface.Ip6Ifaceid = helpers.GetStringPtr(section, "ip6ifaceid")
+	iface.Ip6Gateway = helpers.GetStringPtr(section, "ip6gw")
+	iface.FirewallZone = helpers.GetStringPtr(section, "zone")
+	iface.Metric = helpers.GetUint32Ptr(section, "metric")
+	iface.Txqueuelen = helpers.GetUint32Ptr(section, "txqueuelen")
+	iface.Disabled = helpers.GetBool(section, "disabled")
+	iface.Autostart = helpers.GetBool(section, "auto")
+	iface.ForceLink = helpers.GetBool(section, "force_link")
+	iface.Delegate = helpers.GetBool(section, "delegate")
+	iface.Ipv6 = helpers.GetBool(section, "ipv6")
+	iface.PeerDns = helpers.GetBool(section, "peerdns")
+	iface.DefaultRoute = helpers.GetBool(section, "defaultroute")
+	iface.Broadcast = helpers.GetBool(section, "broadcast")
+	iface.SourceFilter = helpers.GetBool(section, "sourcefilter")
+	iface.Fwmark = helpers.GetString(section, "fwmark")
+	if virtual == nil {
+		iface.Mac = helpers.GetStringPtr(section, "macaddr")
+	}
+	iface.Dns = splitValues(section, "dns")
+	iface.DnsSearch = splitValues(section, "dns_search")
+
+	if !isTunnel && !hasProtocolSettings(protocol) {
+		iface.Addresses = parseInterfaceAddresses(section)
+	}
+	return iface
+}
+
+// parseBridgeDevice copies L2 settings from a DSA bridge device section onto the interface.
+func parseBridgeDevice(device *uci.Section, iface *devicev1.Interface) {
+	iface.BridgeMembers = helpers.GetValues(device, "ports")
+	iface.Mtu = helpers.GetUint32Ptr(device, "mtu")
+	iface.Stp = helpers.GetBool(device, "stp")
+	iface.IgmpSnooping = helpers.GetBool(device, "igmp_snooping")
+
+	bridge := &devicev1.BridgeSettings{}
+	helpers.ApplyOptionsToMessage(bridge, device, nil)
+	if proto.Size(bridge) > 0 {
+		iface.Bridge = bridge
+	}
+}
+
+func parseWireguardInterface(section *uci.Section, iface *devicev1.Interface) {
+	wg := &devicev1.WireguardSettings{
+		PrivateKey: helpers.GetString(section, "private_key"),
+		PublicKey:  helpers.GetString(section, "public_key"),
+		ListenPort: helpers.GetUint32Ptr(section, "listen_port"),
+	}
+	if proto.Size(wg) > 0 {
+		iface.Wireguard = wg
+	}
+	iface.Mtu = helpers.GetUint32Ptr(section, "mtu")
+	iface.NoHostRoute = helpers.GetBool(section, "nohostroute")
+	for _, value := range helpers.GetValues(section, "addresses") {
+		if addr := parseCIDRAddress(value); addr != nil {
+			iface.Addresses = append(iface.Addresses, addr)
+		}
+	}
+}
+
+func parseInterfaceAddresses(section *uci.Section) []*devicev1.InterfaceAddress {
+	var addresses []*devicev1.InterfaceAddress
+
+	// list ipaddr carries several addresses in CIDR form; the gateway goes to the first
+	for idx, ipaddr := range splitValues(section, "ipaddr") {
+		addr := parseCIDRAddress(ipaddr)
+		if addr == nil {
+			continue
+		}
+		if addr.GetMask() == 0 {
+			addr.Mask = netmaskToPrefix(helpers.GetString(section, "netmask"))
+		}
+		if idx == 0 {
+			addr.Gateway = helpers.GetString(section, "gateway")
+		}
+		addresses = append(addresses, addr)
+	}
+	for _, value := range helpers.GetValues(section, "ip6addr") {
+		if addr := parseCIDRAddress(value); addr != nil {
+			addresses = append(addresses, addr)
+		}
+	}
+	return addresses
+}
+
+// parseCIDRAddress converts "address[/prefix]" into a static InterfaceAddress.
+func parseCIDRAddress(value string) *devicev1.InterfaceAddress {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil
+	}
+	addr := &devicev1.InterfaceAddress{
+		Family: "ipv4",
+		Proto:  "static",
+	}
+	host := value
+	if idx := strings.Index(value, "/"); idx >= 0 {
+		host = value[:idx]
+		if prefix, err := strconv.ParseUint(value[idx+1:], 10, 32); err == nil {
+			addr.Mask = uint32(prefix)
+		}
+	}
+	if strings.Contains(host, ":") {
+		addr.Family = "ipv6"
+	}
+	addr.Address = host
+	return addr
+}
+
+func netmaskToPrefix(netmask string) uint32 {
+	if netmask == "" {
+		return 0
+	}
+	ip := net.ParseIP(netmask).To4()
+	if ip == nil {
+		return 0
+	}
+	ones, bits := net.IPMask(ip).Size()
+	if bits == 0 {
+		return 0
+	}
+	return uint32(ones)
+}
+
+// splitValues reads a space-separated option and/or list (both forms appear in the wild).
+func splitValues(section *uci.Section, key string) []string {
+	values := strings.Fields(helpers.GetString(section, key))
+	for _, item := range helpers.GetList(section, key) {
+		values = append(values, strings.Fields(item)...)
+	}
+	return values
+}
+
+func parseRouteSection(section *uci.Section) *devicev1.StaticRoute {
+	route := &devicev1.StaticRoute{
+		Name:   section.Name,
+		Device: helpers.GetString(section, "interface"),
+		Next:   helpers.GetString(section, "gateway"),
+		Source: helpers.GetString(section, "source"),
+		Table:  helpers.GetString(section, "table"),
+		Type:   helpers.GetString(section, "type"),
+		Cost:   helpers.GetUint32Ptr(section, "metric"),
+		Mtu:    helpers.GetUint32Ptr(section, "mtu"),
+		Onlink: helpers.GetBool(section, "onlink"),
+	}
+	target := helpers.GetString(section, "target")
+	if netmask := helpers.GetString(section, "netmask"); netmask != "" && !strings.Contains(target, "/") {
+		target = fmt.Sprintf("%s/%d", target, netmaskToPrefix(netmask))
+	}
+	route.Destination = target
+	return route
+}
+
+func parseRuleSection(section *uci.Section) *openwrtv1.IpRule {
+	return &openwrtv1.IpRule{
+		Name:   section.Name,
+		Action: helpers.GetString(section, "action"),
+		Src:    helpers.GetString(section, "src"),
+		Dest:   helpers.GetString(section, "dest"),
+		In:     helpers.GetString(section, "in"),
+		Out:    helpers.GetString(section, "out"),
+		Lookup: helpers.GetString(section, "lookup"),
+		Mark:   helpers.GetString(section, "mark"),
+		Tos:    helpers.GetUint32Ptr(section, "tos"),
+		Goto:   helpers.GetUint32Ptr(section, "goto"),
+		Invert: helpers.GetBool(section, "invert"),
+	}
+}
+
+func parseWireguardPeerSection(section *uci.Section) *openwrtv1.WireguardPeerConfig {
+	return &openwrtv1.WireguardPeerConfig{
+		Interface:           strings.TrimPrefix(section.Type, "wireguard_"),
+		PublicKey:           helpers.GetString(section, "public_key"),
+		AllowedIps:          helpers.GetValues(section, "allowed_ips"),
+		EndpointHost:        helpers.GetString(section, "endpoint_host"),
+		EndpointPort:        helpers.GetUint32Ptr(section, "endpoint_port"),
+		PresharedKey:        helpers.GetString(section, "preshared_key"),
+		PersistentKeepalive: helpers.GetUint32Ptr(section, "persistent_keepalive"),
+		RouteAllowedIps:     helpers.GetBool(section, "route_allowed_ips"),
+	}
+}
+
+func parseSwitchSection(section *uci.Section) *openwrtv1.SwitchConfig {
+	name := helpers.GetString(section, "name")
+	if name == "" {
+		name = section.Name
+	}
+	return &openwrtv1.SwitchConfig{
+		Name:       name,
+		Reset_:     helpers.GetBool(section, "reset"),
+		EnableVlan: helpers.GetBool(section, "enable_vlan"),
+	}
+}
+
+// parseWirelessPackage restores radios and wireless interfaces.
+func parseWirelessPackage(pkg *uci.Package, msg *openwrtv1.OpenWrtConfig) {
+	anonymous := make(map[string]int)
+	for _, section := range pkg.Sections {
+		switch section.Type {
+		case "wifi-device":
+			if section.Name == "" {
+				continue
+			}
+			radio := &devicev1.Radio{
+				Name:     section.Name,
+				Band:     helpers.GetString(section, "band"),
+				Channel:  helpers.GetUint32Value(section, "channel"),
+				Htmode:   helpers.GetString(section, "htmode"),
+				Country:  helpers.GetString(section, "country"),
+				TxPower:  helpers.GetUint32Value(section, "txpower"),
+				Disabled: helpers.GetBool(section, "disabled"),
+			}
+			if radio.Band == "" {
+				radio.Band = bandFromHwmode(helpers.GetString(section, "hwmode"))
+			}
+			radio.Channels = parseRadioChannels(section, radio.Band)
+			msg.Radios = append(msg.Radios, radio)
+		case "wifi-iface":
+			if iface := parseWifiIfaceSection(section, anonymous); iface != nil {
+				msg.Interfaces = append(msg.Interfaces, iface)
+			}
+		}
+	}
+}
+
+// wifiIfaceName derives the NetJSON interface name of a wifi-iface section:
+// the ifname option wins, then the "wifi_<name>" section naming used by
+// buildWifiIfaceSection, then the raw section name. Anonymous sections are
+// numbered per radio (radio0_1, radio0_2, ...).
+func wifiIfaceName(section *uci.Section, anonymous map[string]int) string {
+	if name := helpers.GetString(section, "ifname"); name != "" {
+		return name
+	}
+	if section.Name != "" && !section.Anonymous {
+		if name, ok := strings.CutPrefix(section.Name, "wifi_"); ok && name != "" {
+			return name
+		}
+		return section.Name
+	}
+	radio := helpers.GetString(section, "device")
+	if radio == "" {
+		radio = "wifi"
+	}
+	anonymous[radio]++
+	return fmt.Sprintf("%s_%d", radio, anonymous[radio])
+}
+
+func parseWifiIfaceSection(section *uci.Section, anonymous map[string]int) *devicev1.Interface {
+	name := wifiIfaceName(section, anonymous)
+
+	wifi := &devicev1.WirelessSettings{
+		Radio:              helpers.GetString(section, "device"),
+		Mode:               unmapWirelessMode(helpers.GetString(section, "mode")),
+		Ssid:               helpers.GetString(section, "ssid"),
+		Bssid:              helpers.GetString(section, "bssid"),
+		Hidden:             helpers.GetBool(section, "hidden"),
+		Wds:                helpers.GetBool(section, "wds"),
+		Wmm:                helpers.GetBool(section, "wmm"),
+		Isolate:            helpers.GetBool(section, "isolate"),
+		Ieee80211R:         helpers.GetBool(section, "ieee80211r"),
+		FtPskGenerateLocal: helpers.GetBool(section, "ft_psk_generate_local"),
+		FtOverDs:           helpers.GetBool(section, "ft_over_ds"),
+		RsnPreauth:         helpers.GetBool(section, "rsn_preauth"),
+		Macfilter:          helpers.GetString(section, "macfilter"),
+		Maclist:            helpers.GetValues(section, "maclist"),
+		// network may be a single option, a space separated option or a list.
+		Network: splitValues(section, "network"),
+	}
+	wifi.Encryption = parseWirelessEncryption(section)
+	parseMeshSettings(section, wifi)
+
+	return &devicev1.Interface{
+		Name:     name,
+		Type:     "wireless",
+		Wireless: wifi,
+	}
+}
+
+// parseWirelessEncryption is the inverse of applyWirelessEncryption.
+// A cipher suffix on the encryption value (psk2+ccmp) is folded into Cipher
+// unless an explicit cipher option is present.
+func parseWirelessEncryption(section *uci.Section) *devicev1.WirelessEncryption {
+	if !helpers.OptionExists(section, "encryption") && !helpers.OptionExists(section, "key") {
+		return nil
+	}
+	encryption, suffix, _ := strings.Cut(helpers.GetString(section, "encryption"), "+")
+	enc := &devicev1.WirelessEncryption{
+		Protocol:       unmapEncryptionProtocol(encryption),
+		Cipher:         helpers.GetString(section, "cipher"),
+		Ieee80211W:     helpers.GetString(section, "ieee80211w"),
+		Key:            helpers.GetString(section, "key"),
+		Server:         helpers.GetString(section, "server"),
+		Port:           helpers.GetUint32Ptr(section, "port"),
+		AcctServer:     helpers.GetString(section, "acct_server"),
+		AcctServerPort: helpers.GetUint32Ptr(section, "acct_port"),
+		Disabled:       helpers.GetBool(section, "disabled"),
+
+		OweTransitionIfname: helpers.GetString(section, "owe_transition_ifname"),
+	}
+	if enc.Cipher == "" {
+		enc.Cipher = suffix
+	}
+	if isEnterpriseEncryption(encryption) {
+		parseEnterpriseSettings(section, enc)
+	}
+	return enc
+}
+
+// unmapWirelessMode is the inverse of mapWirelessMode.
+func unmapWirelessMode(mode string) string {
+	switch strings.ToLower(mode) {
+	case "ap":
+		return "access_point"
+	case "sta":
+		return "station"
+	case "mesh":
+		return "802.11s"
+	default:
+		return mode
+	}
+}
+
+// unmapEncryptionProtocol is the inverse of mapEncryptionProtocol.
+func unmapEncryptionProtocol(encryption string) string {
+	switch strings.ToLower(encryption) {
+	case "":
+		return ""
+	case "none":
+		return "none"
+	case "psk":
+		return "wpa_personal"
+	case "psk2":
+		return "wpa2_personal"
+	case "psk-mixed":
+		return "wpa2_personal_mixed"
+	case "sae":
+		return "wpa3_personal"
+	case "sae-mixed":
+		return "wpa3_personal_mixed"
+	case "wpa":
+		return "wpa_enterprise"
+	case "wpa2":
+		return "wpa2_enterprise"
+	case "wpa-mixed":
+		return "wpa2_enterprise_mixed"
+	case "wpa3":
+		return "wpa3_enterprise"
+	case "wpa3-mixed":
+		return "wpa3_enterprise_mixed"
+	case "owe":
+		return "owe"
+	default:
+		return encryption
+	}
+}
+
+// parseOpenvpnPackage restores OpenVPN instances from the openvpn package.
+func parseOpenvpnPackage(pkg *uci.Package, msg *openwrtv1.OpenWrtConfig) {
+	for _, section := range pkg.Sections {
+		if section.Type != "openvpn" || section.Name == "" {
+			continue
+		}
+		vpn := &openvpnv1.OpenVpnInstance{}
+		helpers.ApplyOptionsToMessage(vpn, section, map[string]struct{}{
+			"name":    {},
+			"enabled": {},
+		})
+		vpn.Name = section.Name
+		// remote entries contain spaces, so they are not split like other lists
+		for _, line := range append(helpers.GetList(section, "remote"), helpers.GetString(section, "remote")) {
+			if remote := openvpndomain.ParseRemote(line); remote != nil {
+				vpn.Remote = append(vpn.Remote, remote)
+			}
+		}
+		vpn.DataCiphers = openvpndomain.ParseDataCiphers(helpers.GetString(section, "data_ciphers"))
+		msg.Openvpn = append(msg.Openvpn, vpn)
+	}
+}
+
+// parseZerotierPackage restores ZeroTier instances from the zerotier package. Network
+// sections are attached to the instance whose join list contains their id; networks that
+// are only joined get a bare entry.
+func parseZerotierPackage(pkg *uci.Package, msg *openwrtv1.OpenWrtConfig) {
+	networks := make(map[string]*zerotierv1.ZerotierJoin)
+	for _, section := range pkg.Sections {
+		if section.Type != "network" {
+			continue
+		}
+		network := &zerotierv1.ZerotierJoin{}
+		helpers.ApplyOptionsToMessage(network, section, nil)
+		if network.GetId() == "" {
+			continue
+		}
+		if network.GetIfname() == "" && section.Name != zerotierIfname(network.GetId()) && !section.Anonymous {
+			network.Ifname = section.Name
+		}
+		networks[network.GetId()] = network
+	}
+
+	for _, section := range pkg.Sections {
+		if section.Type != "zerotier" || section.Name == "" {
+			continue
+		}
+		zt := &zerotierv1.ZerotierNetwork{Name: section.Name}
+		helpers.ApplyOptionsToMessage(zt, section, map[string]struct{}{
+			"name":    {},
+			"enabled": {},
+			"join":    {},
+		})
+		if enabled := helpers.GetBool(section, "enabled"); enabled != nil && !*enabled {
+			zt.Disabled = proto.Bool(true)
+		}
+		for _, id := range splitValues(section, "join") {
+			network, ok := networks[id]
+			if !ok {
+				network = &zerotierv1.ZerotierJoin{Id: id}
+			}
+			zt.Networks = append(zt.Networks, network)
+		}
+		msg.Zerotier = append(msg.Zerotier, zt)
+	}
+}
+
+// parseZerotierLocalConf moves the local.conf files referenced by local_conf_path into
+// the instances' local_conf and returns the remaining files.
+func parseZerotierLocalConf(msg *openwrtv1.OpenWrtConfig, files []*commonv1.IncludedFile) []*commonv1.IncludedFile {
+	var remaining []*commonv1.IncludedFile
+	for _, file := range files {
+		if !applyZerotierLocalConf(msg, file) {
+			remaining = append(remaining, file)
+		}
+	}
+	return remaining
+}
+
+func applyZerotierLocalConf(msg *openwrtv1.OpenWrtConfig, file *commonv1.IncludedFile) bool {
+	for _, zt := range msg.GetZerotier() {
+		if zt.GetLocalConfPath() == "" || zt.GetLocalConfPath() != file.GetPath() {
+			continue
+		}
+		var conf zerotierLocalConf
+		if err := json.Unmarshal([]byte(file.GetContents()), &conf); err != nil {
+			return false
+		}
+		zt.LocalConf = &zerotierv1.ZerotierLocalConf{
+			PrimaryPort:              conf.Settings.PrimaryPort,
+			SecondaryPort:            conf.Settings.SecondaryPort,
+			TertiaryPort:             conf.Settings.TertiaryPort,
+			PortMappingEnabled:       conf.Settings.PortMappingEnabled,
+			AllowSecondaryPort:       conf.Settings.AllowSecondaryPort,
+			AllowTcpFallbackRelay:    conf.Settings.AllowTcpFallbackRelay,
+			InterfacePrefixBlacklist: conf.Settings.InterfacePrefixBlacklist,
+			Bind:                     conf.Settings.Bind,
+			AllowManagementFrom:      conf.Settings.AllowManagementFrom,
+		}
+		return true
+	}
+	return false
+}
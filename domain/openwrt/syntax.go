@@ -0,0 +1,107 @@
+package openwrt
+
+import (
+	"strconv"
+	"strings"
+
+	helpers "github.com/honeybbq/netjsonconfig/domain/utils"
+	"github.com/honeybbq/netjsonconfig/pkg/ast/uci"
+)
+
+// Syntax 表示 network 包使用的 UCI 方言。
+type Syntax int
+
+const (
+	// SyntaxDSA 为 OpenWrt >= 21.02 的语法：bridge 使用 device section 与 ports 列表，
+	// VLAN 使用 bridge-vlan，interface 通过 device 引用设备。
+	SyntaxDSA Syntax = iota
+	// SyntaxLegacy 为 OpenWrt <= 19.07 的语法：interface 上的 type bridge 与 ifname，
+	// VLAN 使用 802.1q 子接口（eth0.10）。
+	SyntaxLegacy
+)
+
+func (s Syntax) String() string {
+	switch s {
+	case SyntaxLegacy:
+		return "legacy"
+	default:
+		return "dsa"
+	}
+}
+
+// SyntaxForVersion 根据 OpenWrt 版本号选择方言：21.02 之前为 legacy，其余为 DSA。
+// 支持 "19.07.10"、"OpenWrt 21.02.3"、"v22.03" 与 "SNAPSHOT" 等写法，无法识别时 ok 为 false。
+func SyntaxForVersion(version string) (syntax Syntax, ok bool) {
+	fields := strings.Fields(version)
+	if len(fields) == 0 {
+		return SyntaxDSA, false
+	}
+	version = strings.TrimPrefix(strings.ToLower(fields[len(fields)-1]), "v")
+	if version == "snapshot" {
+		return SyntaxDSA, true
+	}
+
+	parts := strings.SplitN(version, ".", 3)
+	if len(parts) < 2 {
+		return SyntaxDSA, false
+	}
+	major, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return SyntaxDSA, false
+	}
+	minor, err := strconv.Atoi(strings.TrimRightFunc(parts[1], func(r rune) bool { return r < '0' || r > '9' }))
+	if err != nil {
+		return SyntaxDSA, false
+	}
+	if major < 21 || (major == 21 && minor < 2) {
+		return SyntaxLegacy, true
+	}
+	return SyntaxDSA, true
+}
+
+// DetectSyntax 根据 network 包的内容判断方言。
+// device section 的 ports、bridge-vlan 或 interface 上指向网络设备的 device 选项表示 DSA；
+// interface 上的 type bridge 或 ifname 选项表示 legacy。两者都没有时返回 DSA。
+// qmi、modemmanager 等协议在两种方言中都用 device 指定调制解调器路径，不作为依据。
+func DetectSyntax(doc *uci.Document) Syntax {
+	if doc == nil {
+		return SyntaxDSA
+	}
+	legacy := false
+	for _, pkg := range doc.Packages {
+		if pkg == nil || pkg.Name != "network" {
+			continue
+		}
+		for _, section := range pkg.Sections {
+			switch section.Type {
+			case "bridge-vlan":
+				return SyntaxDSA
+			case "device":
+				if len(helpers.GetValues(section, "ports")) > 0 {
+					return SyntaxDSA
+				}
+			case "interface":
+				if hasNetdevDevice(section) {
+					return SyntaxDSA
+				}
+				if helpers.GetString(section, "type") == "bridge" || helpers.OptionExists(section, "ifname") {
+					legacy = true
+				}
+			}
+		}
+	}
+	if legacy {
+		return SyntaxLegacy
+	}
+	return SyntaxDSA
+}
+
+// hasNetdevDevice 报告 interface 的 device 选项是否引用网络设备。
+// 调制解调器协议的 device 是 /dev 或 /sys 路径，网络设备名不会包含 "/"。
+func hasNetdevDevice(section *uci.Section) bool {
+	device := helpers.GetString(section, "device")
+	if device == "" || strings.Contains(device, "/") {
+		return false
+	}
+	return !isModemProto(helpers.GetString(section, "proto"))
+}
@@ -0,0 +1,75 @@
+package openwrt
+
+import (
+	"fmt"
+	"strings"
+
+	devicev1 "github.com/honeybbq/netjson/gen/go/netjson/device/v1"
+	openwrtv1 "github.com/honeybbq/netjson/gen/go/netjson/openwrt/v1"
+
+	helpers "github.com/honeybbq/netjsonconfig/domain/utils"
+	"github.com/honeybbq/netjsonconfig/pkg/ast/uci"
+	"github.com/honeybbq/netjsonconfig/pkg/nxerrors"
+)
+
+// lookupZone returns the POSIX TZ string of an IANA zone name. Names are accepted in
+// both the tzdata spelling (America/New_York) and LuCI's (America/New York).
+func lookupZone(name string) (string, bool) {
+	posix, ok := zoneinfo[strings.ReplaceAll(name, " ", "_")]
+	return posix, ok
+}
+
+// validateTimezone rejects zone names missing from the zoneinfo table. Any other
+// timezone is taken as a POSIX TZ string and passed through.
+func validateTimezone(msg *openwrtv1.OpenWrtConfig) error {
+	general := msg.GetGeneral()
+	if tz := general.GetTimezone(); isZoneName(tz) {
+		if _, ok := lookupZone(tz); !ok {
+			return nxerrors.New(nxerrors.KindValidation, fmt.Errorf("unknown timezone %q", tz))
+		}
+	}
+	if name := general.GetZonename(); name != "" {
+		if _, ok := lookupZone(name); !ok {
+			return nxerrors.New(nxerrors.KindValidation, fmt.Errorf("unknown zonename %q", name))
+		}
+	}
+	return nil
+}
+
+// isZoneName tells IANA names (Area/Location) from POSIX TZ strings. The latter only
+// contain a slash in their rules, which always follow a comma (M10.5.0/3).
+func isZoneName(tz string) bool {
+	return strings.Contains(tz, "/") && !strings.Contains(tz, ",")
+}
+
+// applyTimezone writes the zone the way OpenWrt expects it: the zone name in zonename,
+// spelled with spaces like LuCI does, and its POSIX TZ string in timezone. A POSIX
+// timezone is kept as is, next to the zonename if one is set.
+func applyTimezone(section *uci.Section, general *devicev1.General) {
+	name, tz := general.GetZonename(), general.GetTimezone()
+	if posix, ok := lookupZone(tz); ok {
+		if name == "" {
+			name = tz
+		}
+		tz = posix
+	} else if posix, ok := lookupZone(name); ok && tz == "" {
+		tz = posix
+	}
+	helpers.SetString(section, "timezone", tz)
+	helpers.SetString(section, "zonename", strings.ReplaceAll(name, "_", " "))
+}
+
+// parseTimezone is the inverse of applyTimezone: a zonename whose table entry matches
+// timezone collapses into timezone, otherwise both are kept.
+func parseTimezone(general *devicev1.General) {
+	if general.GetZonename() == "" {
+		return
+	}
+	name := strings.ReplaceAll(general.GetZonename(), " ", "_")
+	if posix, ok := lookupZone(name); ok && posix == general.GetTimezone() {
+		general.Timezone = name
+		general.Zonename = ""
+		return
+	}
+	general.Zonename = name
+}
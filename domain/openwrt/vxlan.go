@@ -0,0 +1,148 @@
+package openwrt
+
+import (
+	"fmt"
+	"net"
+	"slices"
+	"strings"
+
+	devicev1 "github.com/honeybbq/netjson/gen/go/netjson/device/v1"
+	openwrtv1 "github.com/honeybbq/netjson/gen/go/netjson/openwrt/v1"
+
+	helpers "github.com/honeybbq/netjsonconfig/domain/utils"
+	"github.com/honeybbq/netjsonconfig/pkg/ast/uci"
+)
+
+const (
+	defaultVxlanPort = 4789
+	maxVxlanVNI      = 1<<24 - 1
+)
+
+func isVxlanInterface(iface *devicev1.Interface) bool {
+	return strings.EqualFold(iface.GetType(), "vxlan")
+}
+
+// vxlanVNIs validates the vxlan interfaces and returns the VNI of each one.
+//
+// Explicit VNIs are kept as is and must be unique per UDP port, as the kernel refuses
+// two VXLAN devices with the same VNI and port. Interfaces with auto_vni then receive
+// the lowest VNIs not used by any other interface, in interface order, so the
+// allocation is stable as long as the interface list does not change.
+func vxlanVNIs(msg *openwrtv1.OpenWrtConfig) (map[string]uint32, error) {
+	interfaces := make(map[string]*devicev1.Interface)
+	for _, iface := range msg.GetInterfaces() {
+		interfaces[iface.GetName()] = iface
+	}
+
+	vnis := make(map[string]uint32)
+	used := make(map[uint32]struct{})
+	byPort := make(map[[2]uint32]string)
+	var auto []string
+	for _, iface := range msg.GetInterfaces() {
+		if !isVxlanInterface(iface) {
+			continue
+		}
+		name := iface.GetName()
+		vx := iface.GetVxlan()
+		if net.ParseIP(vx.GetVtep()) == nil {
+			return nil, validationError(fmt.Sprintf("vxlan interface %q", name), "vtep %q is not an IP address", vx.GetVtep())
+		}
+		if tunlink := vx.GetTunlink(); tunlink != "" {
+			target, ok := interfaces[tunlink]
+			if !ok {
+				return nil, validationError(fmt.Sprintf("vxlan interface %q", name), "tunlink references unknown interface %q", tunlink)
+			}
+			if !isWireguardInterface(target) {
+				return nil, validationError(fmt.Sprintf("vxlan interface %q", name), "tunlink %q is not a wireguard interface", tunlink)
+			}
+		}
+		if vx.GetAutoVni() {
+			auto = append(auto, name)
+			continue
+		}
+		if vx.Vni == nil {
+			return nil, validationError(fmt.Sprintf("vxlan interface %q", name), "vni or auto_vni is required")
+		}
+		vni := vx.GetVni()
+		if vni == 0 || vni > maxVxlanVNI {
+			return nil, validationError(fmt.Sprintf("vxlan interface %q", name), "vni %d out of range 1-%d", vni, maxVxlanVNI)
+		}
+		key := [2]uint32{vni, vxlanPort(vx)}
+		if other, ok := byPort[key]; ok {
+			return nil, validationError(fmt.Sprintf("vxlan interface %q", name), "vni %d on port %d already used by %q", vni, key[1], other)
+		}
+		byPort[key] = name
+		used[vni] = struct{}{}
+		vnis[name] = vni
+	}
+
+	next := uint32(1)
+	for _, name := range auto {
+		for {
+			if _, ok := used[next]; !ok {
+				break
+			}
+			next++
+		}
+		if next > maxVxlanVNI {
+			return nil, validationError(fmt.Sprintf("vxlan interface %q", name), "no free vni left")
+		}
+		used[next] = struct{}{}
+		vnis[name] = next
+	}
+	return vnis, nil
+}
+
+// isWireguardInterface reports interfaces rendered with proto wireguard.
+func isWireguardInterface(iface *devicev1.Interface) bool {
+	return strings.EqualFold(iface.GetType(), "wireguard") || iface.GetProto() == "wireguard"
+}
+
+func vxlanPort(vx *devicev1.VxlanSettings) uint32 {
+	if vx.Port != nil {
+		return vx.GetPort()
+	}
+	return defaultVxlanPort
+}
+
+// applyVxlanInterface writes the netifd vxlan/vxlan6 options; the protocol follows the
+// address family of the vtep.
+func applyVxlanInterface(section *uci.Section, iface *devicev1.Interface, vni uint32) {
+	vx := iface.GetVxlan()
+	proto := "vxlan"
+	if ip := net.ParseIP(vx.GetVtep()); ip != nil && ip.To4() == nil {
+		proto = "vxlan6"
+	}
+	helpers.SetString(section, "proto", proto)
+	helpers.SetString(section, "peeraddr", vx.GetVtep())
+	helpers.SetUint32Value(section, "port", vxlanPort(vx))
+	helpers.SetUint32Value(section, "vid", vni)
+	helpers.SetString(section, "tunlink", vx.GetTunlink())
+	helpers.SetUint32Ptr(section, "ttl", vx.Ttl)
+	helpers.SetBool(section, "rxcsum", vx.Rxcsum)
+	helpers.SetBool(section, "txcsum", vx.Txcsum)
+	helpers.SetBool(section, "learning", vx.Learning)
+}
+
+func isVxlanProto(protocol string) bool {
+	return slices.Contains([]string{"vxlan", "vxlan6"}, protocol)
+}
+
+// parseVxlanInterface restores the vxlan settings. The VNI always comes back as an
+// explicit vni since auto allocation is not recorded in UCI.
+func parseVxlanInterface(section *uci.Section, iface *devicev1.Interface) {
+	iface.Type = "vxlan"
+	vx := &devicev1.VxlanSettings{
+		Vtep:     helpers.GetString(section, "peeraddr"),
+		Port:     helpers.GetUint32Ptr(section, "port"),
+		Vni:      helpers.GetUint32Ptr(section, "vid"),
+		Tunlink:  helpers.GetString(section, "tunlink"),
+		Ttl:      helpers.GetUint32Ptr(section, "ttl"),
+		Rxcsum:   helpers.GetBool(section, "rxcsum"),
+		Txcsum:   helpers.GetBool(section, "txcsum"),
+		Learning: helpers.GetBool(section, "learning"),
+	}
+	iface.Vxlan = vx
+	iface.Device = helpers.GetString(section, "device")
+	iface.Mtu = helpers.GetUint32Ptr(section, "mtu")
+}
@@ -0,0 +1,380 @@
+package openwrt
+
+import (
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	devicev1 "github.com/honeybbq/netjson/gen/go/netjson/device/v1"
+	openwrtv1 "github.com/honeybbq/netjson/gen/go/netjson/openwrt/v1"
+
+	helpers "github.com/honeybbq/netjsonconfig/domain/utils"
+	"github.com/honeybbq/netjsonconfig/pkg/ast/uci"
+	"github.com/honeybbq/netjsonconfig/pkg/nxerrors"
+)
+
+// isEnterpriseEncryption reports the 802.1X encryption values of hostapd/wpa_supplicant.
+func isEnterpriseEncryption(encryption string) bool {
+	switch encryption {
+	case "wpa", "wpa2", "wpa-mixed", "wpa3", "wpa3-mixed":
+		return true
+	default:
+		return false
+	}
+}
+
+func isPersonalEncryption(encryption string) bool {
+	switch encryption {
+	case "psk", "psk2", "psk-mixed", "sae", "sae-mixed":
+		return true
+	default:
+		return false
+	}
+}
+
+// validateWireless checks radios and wireless interfaces before rendering.
+func validateWireless(msg *openwrtv1.OpenWrtConfig, syntax Syntax) error {
+	radios := make(map[string]*devicev1.Radio, len(msg.GetRadios()))
+	for _, radio := range msg.GetRadios() {
+		if err := validateRadio(radio, syntax); err != nil {
+			return nxerrors.New(nxerrors.KindValidation, fmt.Errorf("radio %q: %w", radio.GetName(), err))
+		}
+		radios[radio.GetName()] = radio
+	}
+	for _, iface := range msg.GetInterfaces() {
+		wifi := iface.GetWireless()
+		if wifi == nil {
+			continue
+		}
+		if err := validateWirelessEncryption(wifi); err != nil {
+			return validationError(fmt.Sprintf("wireless interface %q", iface.GetName()), "%v", err)
+		}
+		if err := validateWirelessMode(wifi, radios); err != nil {
+			return validationError(fmt.Sprintf("wireless interface %q", iface.GetName()), "%v", err)
+		}
+	}
+	return nil
+}
+
+// htmodeBands lists the bands each htmode is valid on. 320 MHz channels only exist
+// on 6 GHz and VHT is a 5 GHz only PHY.
+var htmodeBands = map[string][]string{
+	"NOHT":   {"2g", "5g", "6g", "60g"},
+	"HT20":   {"2g", "5g"},
+	"HT40":   {"2g", "5g"},
+	"HT40+":  {"2g", "5g"},
+	"HT40-":  {"2g", "5g"},
+	"VHT20":  {"5g"},
+	"VHT40":  {"5g"},
+	"VHT80":  {"5g"},
+	"VHT160": {"5g"},
+	"HE20":   {"2g", "5g", "6g"},
+	"HE40":   {"2g", "5g", "6g"},
+	"HE80":   {"5g", "6g"},
+	"HE160":  {"5g", "6g"},
+	"EHT20":  {"2g", "5g", "6g"},
+	"EHT40":  {"2g", "5g", "6g"},
+	"EHT80":  {"5g", "6g"},
+	"EHT160": {"5g", "6g"},
+	"EHT320": {"6g"},
+}
+
+// validateRadio checks band, htmode and channels. A radio without band only gets
+// its htmode checked since the legal channels depend on the band.
+//
+// Legacy targets (before 21.02) select the band through hwmode, which cannot
+// express 6 GHz, and their mac80211 scripts predate HE/EHT.
+func validateRadio(radio *devicev1.Radio, syntax Syntax) error {
+	band := radio.GetBand()
+	switch band {
+	case "", "2g", "5g", "6g", "60g":
+	default:
+		return fmt.Errorf("invalid band %q (want 2g, 5g, 6g or 60g)", band)
+	}
+
+	if htmode := radio.GetHtmode(); htmode != "" {
+		bands, ok := htmodeBands[htmode]
+		if !ok {
+			return fmt.Errorf("unknown htmode %q", htmode)
+		}
+		if band != "" && !slices.Contains(bands, band) {
+			return fmt.Errorf("htmode %s is not valid on band %s", htmode, band)
+		}
+		if syntax == SyntaxLegacy && (strings.HasPrefix(htmode, "HE") || strings.HasPrefix(htmode, "EHT")) {
+			return fmt.Errorf("htmode %s requires OpenWrt 21.02 or later", htmode)
+		}
+	}
+	if syntax == SyntaxLegacy && band == "6g" {
+		return fmt.Errorf("band 6g requires OpenWrt 21.02 or later")
+	}
+
+	if channel := radio.GetChannel(); channel != 0 {
+		if len(radio.GetChannels()) > 0 {
+			return fmt.Errorf("channels requires channel auto")
+		}
+		if band != "" && !legalChannel(band, channel) {
+			return fmt.Errorf("channel %d is not valid on band %s", channel, band)
+		}
+	}
+	for _, channel := range radio.GetChannels() {
+		if band != "" && !legalChannel(band, channel) {
+			return fmt.Errorf("channels entry %d is not valid on band %s", channel, band)
+		}
+	}
+	return nil
+}
+
+// legalChannel reports the 20 MHz primary channels of each band.
+func legalChannel(band string, channel uint32) bool {
+	switch band {
+	case "2g":
+		return channel >= 1 && channel <= 14
+	case "5g":
+		switch {
+		case channel >= 36 && channel <= 64, channel >= 100 && channel <= 144:
+			return channel%4 == 0
+		case channel >= 149 && channel <= 177:
+			return (channel-149)%4 == 0
+		}
+		return false
+	case "6g":
+		return channel == 2 || (channel >= 1 && channel <= 233 && (channel-1)%4 == 0)
+	case "60g":
+		return channel >= 1 && channel <= 6
+	}
+	return false
+}
+
+// legacyHwmode maps the band onto the hwmode option of OpenWrt 19.07 and older.
+func legacyHwmode(band string) string {
+	switch band {
+	case "2g":
+		return "11g"
+	case "5g":
+		return "11a"
+	case "60g":
+		return "11ad"
+	default:
+		return ""
+	}
+}
+
+// bandFromHwmode is the inverse of legacyHwmode; 11b/11g/11n radios are 2.4 GHz.
+func bandFromHwmode(hwmode string) string {
+	switch hwmode {
+	case "11b", "11g", "11n", "11bg", "11ng":
+		return "2g"
+	case "11a", "11na", "11ac":
+		return "5g"
+	case "11ad":
+		return "60g"
+	default:
+		return ""
+	}
+}
+
+// parseRadioChannels reads the channels list. Ranges such as 36-48 are expanded to
+// the legal channels of the band they cover.
+func parseRadioChannels(section *uci.Section, band string) []uint32 {
+	var channels []uint32
+	for _, value := range splitValues(section, "channels") {
+		low, high, isRange := strings.Cut(value, "-")
+		first, err := strconv.ParseUint(low, 10, 32)
+		if err != nil {
+			continue
+		}
+		last := first
+		if isRange {
+			if last, err = strconv.ParseUint(high, 10, 32); err != nil {
+				continue
+			}
+		}
+		for channel := first; channel <= last; channel++ {
+			if !isRange || band == "" || legalChannel(band, uint32(channel)) {
+				channels = append(channels, uint32(channel))
+			}
+		}
+	}
+	return channels
+}
+
+// validateWirelessMode checks the mesh and WDS settings against the interface mode.
+//
+// 802.11s peers only find each other on the same channel, so a mesh interface needs
+// a mesh_id and a declared radio with a fixed channel; an encrypted mesh uses SAE.
+// WDS (4addr) only pairs an access point with its stations.
+func validateWirelessMode(wifi *devicev1.WirelessSettings, radios map[string]*devicev1.Radio) error {
+	mode := mapWirelessMode(wifi.GetMode())
+	if mode != "mesh" {
+		if hasMeshSettings(wifi) {
+			return fmt.Errorf("mesh options require mode 802.11s, got %q", wifi.GetMode())
+		}
+	} else {
+		if wifi.GetMeshId() == "" {
+			return fmt.Errorf("mesh requires mesh_id")
+		}
+		radio, ok := radios[wifi.GetRadio()]
+		if !ok {
+			return fmt.Errorf("mesh radio %q is not defined", wifi.GetRadio())
+		}
+		if radio.GetChannel() == 0 {
+			return fmt.Errorf("mesh radio %q needs a fixed channel", radio.GetName())
+		}
+		if threshold := wifi.MeshRssiThreshold; threshold != nil && (*threshold < -100 || *threshold > 0) {
+			return fmt.Errorf("mesh_rssi_threshold %d out of range -100-0", *threshold)
+		}
+		if enc := wifi.GetEncryption(); enc != nil {
+			switch mapEncryptionProtocol(enc.GetProtocol()) {
+			case "none", "sae":
+			default:
+				return fmt.Errorf("mesh encryption must be none or wpa3_personal, got %q", enc.GetProtocol())
+			}
+		}
+	}
+
+	if wifi.GetWds() {
+		switch mode {
+		case "ap":
+		case "sta":
+			if wifi.GetSsid() == "" && wifi.GetBssid() == "" {
+				return fmt.Errorf("wds station needs the ssid or bssid of its access point")
+			}
+		default:
+			return fmt.Errorf("wds is only supported in access_point and station mode")
+		}
+	}
+	return nil
+}
+
+func hasMeshSettings(wifi *devicev1.WirelessSettings) bool {
+	return wifi.GetMeshId() != "" || wifi.MeshFwding != nil || wifi.MeshRssiThreshold != nil || wifi.MeshGateAnnouncements != nil
+}
+
+// validateWirelessEncryption rejects protocols UCI does not know and enterprise or
+// personal settings hostapd/wpa_supplicant would refuse to start with.
+func validateWirelessEncryption(wifi *devicev1.WirelessSettings) error {
+	enc := wifi.GetEncryption()
+	if enc == nil {
+		return nil
+	}
+	encryption := mapEncryptionProtocol(enc.GetProtocol())
+	switch {
+	case isPersonalEncryption(encryption):
+		key := enc.GetKey()
+		if strings.HasPrefix(encryption, "psk") && !validPassphrase(key) {
+			return fmt.Errorf("%s key must be 8 to 63 characters or 64 hex digits", enc.GetProtocol())
+		}
+		if key == "" {
+			return fmt.Errorf("%s requires a key", enc.GetProtocol())
+		}
+	case isEnterpriseEncryption(encryption):
+		if mapWirelessMode(wifi.GetMode()) == "sta" {
+			return validateEapCredentials(enc)
+		}
+		if enc.GetServer() == "" || enc.GetAuthSecret() == "" {
+			return fmt.Errorf("%s requires server and auth_secret", enc.GetProtocol())
+		}
+		if enc.DynamicVlan != nil && enc.GetDynamicVlan() > 2 {
+			return fmt.Errorf("dynamic_vlan must be 0, 1 or 2")
+		}
+	case encryption == "none", encryption == "wep", encryption == "owe":
+	default:
+		return fmt.Errorf("unsupported encryption protocol %q", enc.GetProtocol())
+	}
+	return nil
+}
+
+// validPassphrase accepts a WPA passphrase or a raw 256-bit PSK in hex.
+func validPassphrase(key string) bool {
+	if len(key) == 64 {
+		_, err := hex.DecodeString(key)
+		return err == nil
+	}
+	return len(key) >= 8 && len(key) <= 63
+}
+
+func validateEapCredentials(enc *devicev1.WirelessEncryption) error {
+	switch enc.GetEapType() {
+	case "tls":
+		if enc.GetClientCert() == "" || enc.GetPrivKey() == "" {
+			return fmt.Errorf("eap tls requires client_cert and priv_key")
+		}
+	case "peap", "ttls", "fast", "pwd":
+		if enc.GetIdentity() == "" || enc.GetPassword() == "" {
+			return fmt.Errorf("eap %s requires identity and password", enc.GetEapType())
+		}
+	case "":
+		return fmt.Errorf("%s station requires eap_type", enc.GetProtocol())
+	default:
+		return fmt.Errorf("unsupported eap_type %q", enc.GetEapType())
+	}
+	return nil
+}
+
+// applyRadiusSettings writes the 802.1X authenticator options of an access point.
+func applyRadiusSettings(section *uci.Section, enc *devicev1.WirelessEncryption) {
+	helpers.SetString(section, "auth_server", enc.GetServer())
+	helpers.SetUint32Ptr(section, "auth_port", enc.Port)
+	helpers.SetString(section, "auth_secret", enc.GetAuthSecret())
+	helpers.SetString(section, "acct_server", enc.GetAcctServer())
+	helpers.SetUint32Ptr(section, "acct_port", enc.AcctServerPort)
+	helpers.SetString(section, "acct_secret", enc.GetAcctSecret())
+	helpers.SetString(section, "nasid", enc.GetNasid())
+	helpers.SetUint32Ptr(section, "dynamic_vlan", enc.DynamicVlan)
+	helpers.SetString(section, "vlan_tagged_interface", enc.GetVlanTaggedInterface())
+}
+
+// applyEapCredentials writes the wpa_supplicant EAP options of a station.
+func applyEapCredentials(section *uci.Section, enc *devicev1.WirelessEncryption) {
+	helpers.SetString(section, "eap_type", enc.GetEapType())
+	helpers.SetString(section, "auth", enc.GetAuth())
+	helpers.SetString(section, "identity", enc.GetIdentity())
+	helpers.SetString(section, "anonymous_identity", enc.GetAnonymousIdentity())
+	helpers.SetString(section, "password", enc.GetPassword())
+	helpers.SetString(section, "ca_cert", enc.GetCaCert())
+	helpers.SetString(section, "client_cert", enc.GetClientCert())
+	helpers.SetString(section, "priv_key", enc.GetPrivKey())
+	helpers.SetString(section, "priv_key_pwd", enc.GetPrivKeyPwd())
+}
+
+// parseEnterpriseSettings restores the 802.1X options. auth_server and auth_port win
+// over the older server and port spellings.
+func parseEnterpriseSettings(section *uci.Section, enc *devicev1.WirelessEncryption) {
+	if server := helpers.GetString(section, "auth_server"); server != "" {
+		enc.Server = server
+	}
+	if port := helpers.GetUint32Ptr(section, "auth_port"); port != nil {
+		enc.Port = port
+	}
+	enc.AuthSecret = helpers.GetString(section, "auth_secret")
+	enc.AcctSecret = helpers.GetString(section, "acct_secret")
+	enc.Nasid = helpers.GetString(section, "nasid")
+	enc.DynamicVlan = helpers.GetUint32Ptr(section, "dynamic_vlan")
+	enc.VlanTaggedInterface = helpers.GetString(section, "vlan_tagged_interface")
+	enc.EapType = helpers.GetString(section, "eap_type")
+	enc.Auth = helpers.GetString(section, "auth")
+	enc.Identity = helpers.GetString(section, "identity")
+	enc.AnonymousIdentity = helpers.GetString(section, "anonymous_identity")
+	enc.Password = helpers.GetString(section, "password")
+	enc.CaCert = helpers.GetString(section, "ca_cert")
+	enc.ClientCert = helpers.GetString(section, "client_cert")
+	enc.PrivKey = helpers.GetString(section, "priv_key")
+	enc.PrivKeyPwd = helpers.GetString(section, "priv_key_pwd")
+}
+
+// applyMeshSettings writes the 802.11s options; the mesh SAE key is the encryption key.
+func applyMeshSettings(section *uci.Section, wifi *devicev1.WirelessSettings) {
+	helpers.SetString(section, "mesh_id", wifi.GetMeshId())
+	helpers.SetBool(section, "mesh_fwding", wifi.MeshFwding)
+	helpers.SetInt32Ptr(section, "mesh_rssi_threshold", wifi.MeshRssiThreshold)
+	helpers.SetBool(section, "mesh_gate_announcements", wifi.MeshGateAnnouncements)
+}
+
+func parseMeshSettings(section *uci.Section, wifi *devicev1.WirelessSettings) {
+	wifi.MeshId = helpers.GetString(section, "mesh_id")
+	wifi.MeshFwding = helpers.GetBool(section, "mesh_fwding")
+	wifi.MeshRssiThreshold = helpers.GetInt32Ptr(section, "mesh_rssi_threshold")
+	wifi.MeshGateAnnouncements = helpers.GetBool(section, "mesh_gate_announcements")
+}
@@ -6,6 +6,7 @@ import (
 
 	"google.golang.org/protobuf/encoding/protojson"
 	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
 
 	"github.com/honeybbq/netjsonconfig/pkg/ast/uci"
 )
@@ -15,10 +16,7 @@ func SetString(section *uci.Section, key, value string) {
 	if section == nil || value == "" {
 		return
 	}
-	if section.Options == nil {
-		section.Options = make(map[string][]string)
-	}
-	section.Options[key] = []string{value}
+	section.SetOption(key, value)
 }
 
 // SetStringPtr stores the pointed string if not nil/empty.
@@ -34,10 +32,15 @@ func SetUint32Ptr(section *uci.Section, key string, value *uint32) {
 	if section == nil || value == nil {
 		return
 	}
-	if section.Options == nil {
-		section.Options = make(map[string][]string)
+	section.SetOption(key, strconv.FormatUint(uint64(*value), 10))
+}
+
+// SetInt32Ptr stores int32 pointer as decimal string.
+func SetInt32Ptr(section *uci.Section, key string, value *int32) {
+	if section == nil || value == nil {
+		return
 	}
-	section.Options[key] = []string{strconv.FormatUint(uint64(*value), 10)}
+	section.SetOption(key, strconv.FormatInt(int64(*value), 10))
 }
 
 // SetUint32Value stores uint32 value as decimal string if non-zero.
@@ -45,10 +48,7 @@ func SetUint32Value(section *uci.Section, key string, value uint32) {
 	if section == nil || value == 0 {
 		return
 	}
-	if section.Options == nil {
-		section.Options = make(map[string][]string)
-	}
-	section.Options[key] = []string{strconv.FormatUint(uint64(value), 10)}
+	section.SetOption(key, strconv.FormatUint(uint64(value), 10))
 }
 
 // SetBool stores bool pointer as "1"/"0".
@@ -64,13 +64,10 @@ func SetBoolValue(section *uci.Section, key string, value bool) {
 	if section == nil {
 		return
 	}
-	if section.Options == nil {
-		section.Options = make(map[string][]string)
-	}
 	if value {
-		section.Options[key] = []string{"1"}
+		section.SetOption(key, "1")
 	} else {
-		section.Options[key] = []string{"0"}
+		section.SetOption(key, "0")
 	}
 }
 
@@ -88,10 +85,7 @@ func SetList(section *uci.Section, key string, values []string) {
 	if len(filtered) == 0 {
 		return
 	}
-	if section.Lists == nil {
-		section.Lists = make(map[string][]string)
-	}
-	section.Lists[key] = filtered
+	section.SetList(key, filtered)
 }
 
 // AppendList appends a single value to a list option.
@@ -99,19 +93,12 @@ func AppendList(section *uci.Section, key string, value string) {
 	if section == nil || value == "" {
 		return
 	}
-	if section.Lists == nil {
-		section.Lists = make(map[string][]string)
-	}
-	section.Lists[key] = append(section.Lists[key], value)
+	section.AddList(key, value)
 }
 
 // OptionExists reports whether option already set.
 func OptionExists(section *uci.Section, key string) bool {
-	if section == nil {
-		return false
-	}
-	_, ok := section.Options[key]
-	return ok
+	return section.HasOption(key)
 }
 
 // ProtoMessageToMap converts proto message into map via protojson.
@@ -185,3 +172,173 @@ func toStringSlice(items []any) []string {
 	}
 	return result
 }
+
+// GetString returns the first value of an option, or "" if unset.
+func GetString(section *uci.Section, key string) string {
+	value, _ := section.Option(key)
+	return value
+}
+
+// GetStringPtr returns a pointer to the option value, or nil if unset.
+func GetStringPtr(section *uci.Section, key string) *string {
+	if !OptionExists(section, key) {
+		return nil
+	}
+	value := GetString(section, key)
+	return &value
+}
+
+// GetUint32Ptr parses an option as uint32, returning nil if unset or invalid.
+func GetUint32Ptr(section *uci.Section, key string) *uint32 {
+	value := GetString(section, key)
+	if value == "" {
+		return nil
+	}
+	parsed, err := strconv.ParseUint(value, 10, 32)
+	if err != nil {
+		return nil
+	}
+	result := uint32(parsed)
+	return &result
+}
+
+// GetInt32Ptr parses an option as int32, returning nil if unset or invalid.
+func GetInt32Ptr(section *uci.Section, key string) *int32 {
+	value := GetString(section, key)
+	if value == "" {
+		return nil
+	}
+	parsed, err := strconv.ParseInt(value, 10, 32)
+	if err != nil {
+		return nil
+	}
+	result := int32(parsed)
+	return &result
+}
+
+// GetUint32Value parses an option as uint32, returning 0 if unset or invalid.
+func GetUint32Value(section *uci.Section, key string) uint32 {
+	if value := GetUint32Ptr(section, key); value != nil {
+		return *value
+	}
+	return 0
+}
+
+// GetBool parses an option using UCI boolean semantics, returning nil if unset or invalid.
+func GetBool(section *uci.Section, key string) *bool {
+	value, ok := ParseBool(GetString(section, key))
+	if !ok {
+		return nil
+	}
+	return &value
+}
+
+// ParseBool accepts the boolean spellings understood by UCI ("1", "yes", "on", "true", "enabled" and their negations).
+func ParseBool(value string) (bool, bool) {
+	switch value {
+	case "1", "yes", "on", "true", "enabled":
+		return true, true
+	case "0", "no", "off", "false", "disabled":
+		return false, true
+	default:
+		return false, false
+	}
+}
+
+// GetList returns the list values stored under key.
+func GetList(section *uci.Section, key string) []string {
+	return section.List(key)
+}
+
+// GetValues returns the list values stored under key, or the option value when key is
+// stored as an option: `uci show` prints a single-value list like an option.
+func GetValues(section *uci.Section, key string) []string {
+	if values := section.List(key); len(values) > 0 {
+		return values
+	}
+	if value := GetString(section, key); value != "" {
+		return []string{value}
+	}
+	return nil
+}
+
+// ApplyOptionsToMessage is the inverse of ApplyOptionsFromMap: it copies options and lists
+// into same-named scalar fields of msg, converting values according to the field kind.
+// Unknown keys, message fields and values that fail to convert are skipped.
+func ApplyOptionsToMessage(msg proto.Message, section *uci.Section, skip map[string]struct{}) {
+	if msg == nil || section == nil {
+		return
+	}
+	refl := msg.ProtoReflect()
+	fields := refl.Descriptor().Fields()
+
+	apply := func(key string, values []string) {
+		if skip != nil {
+			if _, ok := skip[key]; ok {
+				return
+			}
+		}
+		fd := fields.ByName(protoreflect.Name(key))
+		if fd == nil || fd.Kind() == protoreflect.MessageKind || fd.Kind() == protoreflect.GroupKind || fd.IsMap() {
+			return
+		}
+		if fd.IsList() {
+			list := refl.Mutable(fd).List()
+			for _, raw := range values {
+				if value, ok := convertScalar(fd, raw); ok {
+					list.Append(value)
+				}
+			}
+			return
+		}
+		if len(values) == 0 {
+			return
+		}
+		if value, ok := convertScalar(fd, values[len(values)-1]); ok {
+			refl.Set(fd, value)
+		}
+	}
+
+	for _, key := range section.OptionKeys() {
+		value, _ := section.Option(key)
+		apply(key, []string{value})
+	}
+	for _, key := range section.ListKeys() {
+		apply(key, section.List(key))
+	}
+}
+
+func convertScalar(fd protoreflect.FieldDescriptor, raw string) (protoreflect.Value, bool) {
+	switch fd.Kind() {
+	case protoreflect.StringKind:
+		return protoreflect.ValueOfString(raw), true
+	case protoreflect.BoolKind:
+		value, ok := ParseBool(raw)
+		return protoreflect.ValueOfBool(value), ok
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		value, err := strconv.ParseUint(raw, 10, 32)
+		return protoreflect.ValueOfUint32(uint32(value)), err == nil
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		value, err := strconv.ParseUint(raw, 10, 64)
+		return protoreflect.ValueOfUint64(value), err == nil
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		value, err := strconv.ParseInt(raw, 10, 32)
+		return protoreflect.ValueOfInt32(int32(value)), err == nil
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		value, err := strconv.ParseInt(raw, 10, 64)
+		return protoreflect.ValueOfInt64(value), err == nil
+	case protoreflect.FloatKind:
+		value, err := strconv.ParseFloat(raw, 32)
+		return protoreflect.ValueOfFloat32(float32(value)), err == nil
+	case protoreflect.DoubleKind:
+		value, err := strconv.ParseFloat(raw, 64)
+		return protoreflect.ValueOfFloat64(value), err == nil
+	case protoreflect.EnumKind:
+		if ev := fd.Enum().Values().ByName(protoreflect.Name(raw)); ev != nil {
+			return protoreflect.ValueOfEnum(ev.Number()), true
+		}
+		return protoreflect.Value{}, false
+	default:
+		return protoreflect.Value{}, false
+	}
+}
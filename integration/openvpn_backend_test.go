@@ -4,6 +4,7 @@ import (
 	"context"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	openvpnv1 "github.com/honeybbq/netjson/gen/go/netjson/openvpn/v1"
@@ -58,3 +59,34 @@ func TestOpenVpnRenderServer(t *testing.T) {
 		}
 	}
 }
+
+func TestOpenVpnRenderPush(t *testing.T) {
+	t.Parallel()
+
+	payload := `{"openvpn": [{
+		"name": "push-server",
+		"mode": "server",
+		"push": ["route 10.0.0.0 255.255.255.0", "dhcp-option DOMAIN café.example", "setenv NOTE \"a\\b\""]
+	}]}`
+	var cfg openvpnv1.OpenVpnConfig
+	if err := protojson.Unmarshal([]byte(payload), &cfg); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+
+	backend := openvpnbackend.New(openvpnrenderer.NewPlainTextRenderer(), openvpnrenderer.NewNotImplementedParser())
+	bundle, err := backend.ToNative(context.Background(), &cfg, netjsonconfig.RenderOptions{})
+	if err != nil {
+		t.Fatalf("ToNative: %v", err)
+	}
+
+	got := bundleToText(bundle)
+	for _, want := range []string{
+		`push "route 10.0.0.0 255.255.255.0"`,
+		`push "dhcp-option DOMAIN café.example"`,
+		`push "setenv NOTE \"a\\b\""`,
+	} {
+		if !strings.Contains(got, want+"\n") {
+			t.Errorf("missing directive %s in:\n%s", want, got)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package integration
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	openwrtbackend "github.com/honeybbq/netjsonconfig/backend/openwrt"
+	"github.com/honeybbq/netjsonconfig/pkg/netjsonconfig"
+	"github.com/honeybbq/netjsonconfig/pkg/nxerrors"
+	ucirenderer "github.com/honeybbq/netjsonconfig/pkg/renderer/uci"
+)
+
+// TestOpenWrtCustomPackages renders testdata/openwrt/custom_packages.json and compares it with custom_packages.uci.
+func TestOpenWrtCustomPackages(t *testing.T) {
+	t.Parallel()
+	assertOpenWrtGolden(t, "custom_packages")
+}
+
+// TestOpenWrtCustomPackagesValidation checks the package, section and option rules of custom packages.
+func TestOpenWrtCustomPackagesValidation(t *testing.T) {
+	t.Parallel()
+
+	assertOpenWrtValidation(t, map[string]openwrtValidationCase{
+		"missing name":         {payload: `{"packages": [{"sections": [{"config_name": "core"}]}]}`, want: `package #1 has invalid name ""`},
+		"managed package":      {payload: `{"packages": [{"name": "network", "sections": [{"config_name": "interface", "config_value": "lan"}]}]}`, want: `package "network" is generated from the typed configuration`},
+		"duplicate package":    {payload: `{"packages": [{"name": "luci"}, {"name": "luci"}]}`, want: `duplicate package "luci"`},
+		"missing section type": {payload: `{"packages": [{"name": "luci", "sections": [{"config_value": "main"}]}]}`, want: `package "luci" section #1: config_name "" is not a valid section type`},
+		"invalid option name":  {payload: `{"packages": [{"name": "luci", "sections": [{"config_name": "core", "media-url": "/x"}]}]}`, want: `package "luci" section #1: "media-url" is not a valid option name`},
+		"nested object":        {payload: `{"packages": [{"name": "luci", "sections": [{"config_name": "core", "themes": {"a": "b"}}]}]}`, want: `package "luci" section #1: option themes: only strings, numbers, booleans and lists of them are supported`},
+		"duplicate section":    {payload: `{"packages": [{"name": "luci", "sections": [{"config_name": "core", "config_value": "main"}, {"config_name": "internal", "config_value": "main"}]}]}`, want: `package "luci" has duplicate section "main"`},
+	})
+}
+
+// TestOpenWrtParseWithoutPackageLine rejects sections that cannot be attributed to a
+// package instead of dropping them.
+func TestOpenWrtParseWithoutPackageLine(t *testing.T) {
+	t.Parallel()
+
+	input := `
+config core 'main'
+	option lang 'auto'
+
+package system
+
+config system
+	option hostname 'router'
+`
+	bundle := &netjsonconfig.Bundle{Packages: []netjsonconfig.Package{{Name: "main", Content: []byte(input)}}}
+	backend := openwrtbackend.New(ucirenderer.NewPlainTextRenderer(), ucirenderer.NewPlainTextParser())
+	_, err := backend.ToNetJSON(context.Background(), bundle, netjsonconfig.ParseOptions{})
+	var nxErr *nxerrors.Error
+	if !errors.As(err, &nxErr) || nxErr.Kind != nxerrors.KindParse {
+		t.Fatalf("expected parse error, got %v", err)
+	}
+	if nxErr.Line != 2 || !strings.Contains(err.Error(), "before any package line") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,26 @@
+package integration
+
+import "testing"
+
+// TestOpenWrtDHCP renders testdata/openwrt/dhcp.json and compares it with dhcp.uci.
+func TestOpenWrtDHCP(t *testing.T) {
+	t.Parallel()
+	assertOpenWrtGolden(t, "dhcp")
+}
+
+// TestOpenWrtDHCPValidation checks the dhcp pool, host and domain rules.
+func TestOpenWrtDHCPValidation(t *testing.T) {
+	t.Parallel()
+
+	lan := `{"name": "lan", "proto": "static", "addresses": [{"family": "ipv4", "proto": "static", "address": "192.168.1.1", "mask": 24}]}`
+	assertOpenWrtValidation(t, map[string]openwrtValidationCase{
+		"unknown interface":   {payload: `{"interfaces": [` + lan + `], "dhcp": {"pools": [{"interface": "guest"}]}}`, want: `pool references unknown interface "guest"`},
+		"no static address":   {payload: `{"interfaces": [{"name": "wan", "proto": "dhcp"}], "dhcp": {"pools": [{"interface": "wan"}]}}`, want: `pool interface "wan" has no static ipv4 address`},
+		"range outside":       {payload: `{"interfaces": [` + lan + `], "dhcp": {"pools": [{"interface": "lan", "start": 200, "limit": 100}]}}`, want: `pool "lan" range start 200 limit 100 does not fit the interface subnet`},
+		"invalid ra mode":     {payload: `{"interfaces": [` + lan + `], "dhcp": {"pools": [{"interface": "lan", "ra": "enabled"}]}}`, want: `pool "lan" has invalid ra mode "enabled"`},
+		"dhcpv4 relay":        {payload: `{"interfaces": [` + lan + `], "dhcp": {"pools": [{"interface": "lan", "dhcpv4": "relay"}]}}`, want: `pool "lan" has invalid dhcpv4 mode "relay"`},
+		"duplicate pool":      {payload: `{"interfaces": [` + lan + `], "dhcp": {"pools": [{"interface": "lan"}, {"interface": "lan"}]}}`, want: `duplicate pool for interface "lan"`},
+		"invalid host ip":     {payload: `{"dhcp": {"hosts": [{"name": "nas", "ip": "192.168.1"}]}}`, want: `host "nas" has invalid ip "192.168.1"`},
+		"domain without name": {payload: `{"dhcp": {"domains": [{"ip": "192.168.1.1"}]}}`, want: `domain #1 needs a name and a valid ip`},
+	})
+}
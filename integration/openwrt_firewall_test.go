@@ -0,0 +1,71 @@
+package integration
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	openwrtv1 "github.com/honeybbq/netjson/gen/go/netjson/openwrt/v1"
+
+	openwrtbackend "github.com/honeybbq/netjsonconfig/backend/openwrt"
+	"github.com/honeybbq/netjsonconfig/pkg/netjsonconfig"
+	ucirenderer "github.com/honeybbq/netjsonconfig/pkg/renderer/uci"
+)
+
+// TestOpenWrtFirewallParse parses a stock style firewall package with anonymous sections
+// and space separated lists.
+func TestOpenWrtFirewallParse(t *testing.T) {
+	t.Parallel()
+
+	input := `package firewall
+
+config zone
+	option name 'wan'
+	option network 'wan wan6'
+	option masq '1'
+
+config rule
+	option name 'Allow-Ping'
+	option src 'wan'
+	option proto 'icmp'
+	option target 'ACCEPT'
+
+config include
+	option path '/etc/firewall.user'
+`
+	bundle := &netjsonconfig.Bundle{Packages: []netjsonconfig.Package{{Name: "main", Content: []byte(input)}}}
+	backend := openwrtbackend.New(ucirenderer.NewPlainTextRenderer(), ucirenderer.NewPlainTextParser())
+	parsed, err := backend.ToNetJSON(context.Background(), bundle, netjsonconfig.ParseOptions{})
+	if err != nil {
+		t.Fatalf("ToNetJSON failed: %v", err)
+	}
+
+	fw := parsed.(*openwrtv1.OpenWrtConfig).GetFirewall()
+	if len(fw.GetZones()) != 1 || strings.Join(fw.GetZones()[0].GetNetwork(), ",") != "wan,wan6" || !fw.GetZones()[0].GetMasq() {
+		t.Fatalf("unexpected zones: %v", fw.GetZones())
+	}
+	if len(fw.GetRules()) != 1 || strings.Join(fw.GetRules()[0].GetProto(), ",") != "icmp" {
+		t.Fatalf("unexpected rules: %v", fw.GetRules())
+	}
+	if len(fw.GetIncludes()) != 1 || fw.GetIncludes()[0].GetName() != "" || fw.GetIncludes()[0].GetPath() != "/etc/firewall.user" {
+		t.Fatalf("unexpected includes: %v", fw.GetIncludes())
+	}
+}
+
+// TestOpenWrtFirewall renders testdata/openwrt/firewall.json and compares it with firewall.uci.
+func TestOpenWrtFirewall(t *testing.T) {
+	t.Parallel()
+	assertOpenWrtGolden(t, "firewall")
+}
+
+// TestOpenWrtFirewallValidation checks the firewall zone and forwarding rules.
+func TestOpenWrtFirewallValidation(t *testing.T) {
+	t.Parallel()
+
+	assertOpenWrtValidation(t, map[string]openwrtValidationCase{
+		"undefined interface zone": {payload: `{"interfaces": [{"name": "lan", "proto": "dhcp", "firewall_zone": "dmz"}], "firewall": {"zones": [{"name": "lan"}]}}`, want: `interface "lan" references undefined zone "dmz"`},
+		"undefined forwarding":     {payload: `{"firewall": {"zones": [{"name": "lan"}], "forwardings": [{"src": "lan", "dest": "wan"}]}}`, want: `forwarding #1 references undefined zone "wan"`},
+		"duplicate zone":           {payload: `{"firewall": {"zones": [{"name": "lan"}, {"name": "lan"}]}}`, want: `duplicate zone "lan"`},
+		"unnamed zone":             {payload: `{"firewall": {"zones": [{"input": "ACCEPT"}]}}`, want: `zone #1 has no name`},
+	})
+}
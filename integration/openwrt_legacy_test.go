@@ -0,0 +1,153 @@
+package integration
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"google.golang.org/protobuf/encoding/protojson"
+
+	openwrtv1 "github.com/honeybbq/netjson/gen/go/netjson/openwrt/v1"
+
+	openwrtbackend "github.com/honeybbq/netjsonconfig/backend/openwrt"
+	"github.com/honeybbq/netjsonconfig/pkg/netjsonconfig"
+	ucirenderer "github.com/honeybbq/netjsonconfig/pkg/renderer/uci"
+)
+
+// TestOpenWrtLegacyRendering renders NetJSON with RenderModeLegacy (OpenWrt <= 19.07).
+func TestOpenWrtLegacyRendering(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		input  string
+		golden string
+	}{
+		{input: "interface_bridge.json", golden: "interface_bridge_legacy.uci"},
+		{input: "vlan_filtering.json", golden: "vlan_filtering_legacy.uci"},
+		{input: "dns_openvpn.json", golden: "dns_openvpn_legacy.uci"},
+		{input: "virtual_devices.json", golden: "virtual_devices_legacy.uci"},
+		{input: "multi_address.json", golden: "multi_address_legacy.uci"},
+		{input: "wireless.json", golden: "wireless_legacy.uci"},
+		{input: "wan_protocols.json", golden: "wan_protocols_legacy.uci"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.golden, func(t *testing.T) {
+			t.Parallel()
+
+			payload, err := os.ReadFile(filepath.Join("..", "testdata", "openwrt", tc.input))
+			if err != nil {
+				t.Fatalf("read netjson: %v", err)
+			}
+			var device openwrtv1.OpenWrtConfig
+			if err := protojson.Unmarshal(payload, &device); err != nil {
+				t.Fatalf("unmarshal netjson: %v", err)
+			}
+
+			backend := openwrtbackend.New(ucirenderer.NewPlainTextRenderer(), ucirenderer.NewPlainTextParser())
+			bundle, err := backend.ToNative(context.Background(), &device, netjsonconfig.RenderOptions{Mode: netjsonconfig.RenderModeLegacy})
+			if err != nil {
+				t.Fatalf("ToNative failed: %v", err)
+			}
+
+			got := bundleToText(bundle)
+			wantBytes, err := os.ReadFile(filepath.Join("..", "testdata", "openwrt", tc.golden))
+			if err != nil {
+				t.Fatalf("read expected: %v", err)
+			}
+			want := string(wantBytes)
+			if !compareConfigs(got, want) {
+				t.Fatalf("%s", formatConfigDiff(got, want))
+			}
+		})
+	}
+}
+
+// TestOpenWrtLegacyParseRoundTrip parses legacy output without any hint, relying on
+// syntax detection, and renders it again with legacy syntax.
+func TestOpenWrtLegacyParseRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	for _, golden := range []string{"interface_bridge_legacy.uci", "vlan_filtering_legacy.uci", "dns_openvpn_legacy.uci", "virtual_devices_legacy.uci", "multi_address_legacy.uci", "wireless_legacy.uci", "wan_protocols_legacy.uci"} {
+		t.Run(golden, func(t *testing.T) {
+			t.Parallel()
+
+			wantBytes, err := os.ReadFile(filepath.Join("..", "testdata", "openwrt", golden))
+			if err != nil {
+				t.Fatalf("read golden: %v", err)
+			}
+			bundle := &netjsonconfig.Bundle{
+				Packages: []netjsonconfig.Package{{Name: "main", Content: wantBytes}},
+			}
+
+			backend := openwrtbackend.New(ucirenderer.NewPlainTextRenderer(), ucirenderer.NewPlainTextParser())
+			parsed, err := backend.ToNetJSON(context.Background(), bundle, netjsonconfig.ParseOptions{})
+			if err != nil {
+				t.Fatalf("ToNetJSON failed: %v", err)
+			}
+			rendered, err := backend.ToNative(context.Background(), parsed, netjsonconfig.RenderOptions{Mode: netjsonconfig.RenderModeLegacy})
+			if err != nil {
+				t.Fatalf("ToNative failed: %v", err)
+			}
+
+			got := bundleToText(rendered)
+			want := string(wantBytes)
+			if !compareConfigs(got, want) {
+				t.Fatalf("%s", formatConfigDiff(got, want))
+			}
+		})
+	}
+}
+
+// TestOpenWrtAutoSyntax checks RenderModeAuto version hints and the recorded syntax metadata.
+func TestOpenWrtAutoSyntax(t *testing.T) {
+	t.Parallel()
+
+	payload, err := os.ReadFile(filepath.Join("..", "testdata", "openwrt", "interface_bridge.json"))
+	if err != nil {
+		t.Fatalf("read netjson: %v", err)
+	}
+	var device openwrtv1.OpenWrtConfig
+	if err := protojson.Unmarshal(payload, &device); err != nil {
+		t.Fatalf("unmarshal netjson: %v", err)
+	}
+
+	tests := []struct {
+		opts   netjsonconfig.RenderOptions
+		syntax string
+		golden string
+	}{
+		{opts: netjsonconfig.RenderOptions{}, syntax: "dsa", golden: "interface_bridge.uci"},
+		{opts: netjsonconfig.RenderOptions{TargetVersion: "19.07.10"}, syntax: "legacy", golden: "interface_bridge_legacy.uci"},
+		{opts: netjsonconfig.RenderOptions{TargetVersion: "OpenWrt 18.06.9"}, syntax: "legacy", golden: "interface_bridge_legacy.uci"},
+		{opts: netjsonconfig.RenderOptions{TargetVersion: "21.02.0"}, syntax: "dsa", golden: "interface_bridge.uci"},
+		{opts: netjsonconfig.RenderOptions{TargetVersion: "SNAPSHOT"}, syntax: "dsa", golden: "interface_bridge.uci"},
+		{opts: netjsonconfig.RenderOptions{Mode: netjsonconfig.RenderModeDSA, TargetVersion: "19.07"}, syntax: "dsa", golden: "interface_bridge.uci"},
+	}
+
+	backend := openwrtbackend.New(ucirenderer.NewPlainTextRenderer(), ucirenderer.NewPlainTextParser())
+	for _, tt := range tests {
+		bundle, err := backend.ToNative(context.Background(), &device, tt.opts)
+		if err != nil {
+			t.Fatalf("ToNative(%+v) failed: %v", tt.opts, err)
+		}
+		if got := bundle.Metadata.Custom[openwrtbackend.MetadataSyntaxKey]; got != tt.syntax {
+			t.Errorf("version %q: recorded syntax %q, want %q", tt.opts.TargetVersion, got, tt.syntax)
+		}
+		if got := bundle.Metadata.Custom[openwrtbackend.MetadataTargetVersionKey]; got != tt.opts.TargetVersion {
+			t.Errorf("version %q: recorded target version %q", tt.opts.TargetVersion, got)
+		}
+		if bundle.Metadata.Version != "" {
+			t.Errorf("version %q: Metadata.Version overwritten with %q", tt.opts.TargetVersion, bundle.Metadata.Version)
+		}
+
+		wantBytes, err := os.ReadFile(filepath.Join("..", "testdata", "openwrt", tt.golden))
+		if err != nil {
+			t.Fatalf("read expected: %v", err)
+		}
+		if got, want := bundleToText(bundle), string(wantBytes); !compareConfigs(got, want) {
+			t.Errorf("version %q:\n%s", tt.opts.TargetVersion, formatConfigDiff(got, want))
+		}
+	}
+}
@@ -0,0 +1,19 @@
+package integration
+
+import "testing"
+
+// TestOpenWrtMultiAddress renders testdata/openwrt/multi_address.json and compares it with multi_address.uci.
+func TestOpenWrtMultiAddress(t *testing.T) {
+	t.Parallel()
+	assertOpenWrtGolden(t, "multi_address")
+}
+
+// TestOpenWrtMultiAddressValidation checks the gateway rules of static addresses.
+func TestOpenWrtMultiAddressValidation(t *testing.T) {
+	t.Parallel()
+
+	assertOpenWrtValidation(t, map[string]openwrtValidationCase{
+		"conflicting gateways": {payload: `{"interfaces": [{"name": "wan", "proto": "static", "addresses": [{"family": "ipv4", "proto": "static", "address": "192.0.2.2", "mask": 24, "gateway": "192.0.2.1"}, {"family": "ipv4", "proto": "static", "address": "198.51.100.2", "mask": 24, "gateway": "198.51.100.1"}]}]}`, want: `conflicting gateways "192.0.2.1" and "198.51.100.1"`},
+		"ipv6 gateway on ipv4": {payload: `{"interfaces": [{"name": "wan", "proto": "static", "addresses": [{"family": "ipv4", "proto": "static", "address": "192.0.2.2", "mask": 24, "gateway": "2001:db8::1"}]}]}`, want: `gateway "2001:db8::1" of 192.0.2.2 is not an IPv4 address`},
+	})
+}
@@ -0,0 +1,9 @@
+package integration
+
+import "testing"
+
+// TestOpenWrtOpenvpnInstances renders testdata/openwrt/openvpn_instances.json and compares it with openvpn_instances.uci.
+func TestOpenWrtOpenvpnInstances(t *testing.T) {
+	t.Parallel()
+	assertOpenWrtGolden(t, "openvpn_instances")
+}
@@ -0,0 +1,104 @@
+package integration
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	openwrtv1 "github.com/honeybbq/netjson/gen/go/netjson/openwrt/v1"
+
+	openwrtbackend "github.com/honeybbq/netjsonconfig/backend/openwrt"
+	"github.com/honeybbq/netjsonconfig/pkg/netjsonconfig"
+	ucirenderer "github.com/honeybbq/netjsonconfig/pkg/renderer/uci"
+)
+
+// TestOpenWrtParseGoldenRoundTrip parses the rendered golden files and renders them again.
+func TestOpenWrtParseGoldenRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	goldens := []string{
+		"system_simple.uci",
+		"system_full.uci",
+		"system_leds.uci",
+		"interface_bridge.uci",
+		"routes_rules.uci",
+		"switches.uci",
+		"wireless.uci",
+		"wireguard_interface.uci",
+		"wireguard_peers.uci",
+		"dns_openvpn.uci",
+		"firewall.uci",
+		"dhcp.uci",
+		"zerotier.uci",
+		"vxlan_wireguard.uci",
+		"wan_protocols.uci",
+		"virtual_devices.uci",
+		"multi_address.uci",
+		"wireless_enterprise.uci",
+		"wireless_mesh.uci",
+		"wireless_radios.uci",
+		"custom_packages.uci",
+		"system_timezone.uci",
+		"openvpn_instances.uci",
+	}
+
+	for _, name := range goldens {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			wantBytes, err := os.ReadFile(filepath.Join("..", "testdata", "openwrt", name))
+			if err != nil {
+				t.Fatalf("read golden: %v", err)
+			}
+			bundle := &netjsonconfig.Bundle{
+				Packages: []netjsonconfig.Package{{Name: "main", Content: wantBytes}},
+			}
+
+			doc, err := ucirenderer.NewPlainTextParser().Parse(context.Background(), bundle, netjsonconfig.ParseOptions{})
+			if err != nil {
+				t.Fatalf("Parse failed: %v", err)
+			}
+			rendered, err := ucirenderer.NewPlainTextRenderer().Render(context.Background(), doc, netjsonconfig.RenderOptions{})
+			if err != nil {
+				t.Fatalf("Render failed: %v", err)
+			}
+
+			got := bundleToText(rendered)
+			want := string(wantBytes)
+			if !compareConfigs(got, want) {
+				t.Fatalf("%s", formatConfigDiff(got, want))
+			}
+		})
+	}
+}
+
+// TestOpenWrtParseShowSingleValueList reads `uci show` lists that have a single value,
+// which the parser can only return as options.
+func TestOpenWrtParseShowSingleValueList(t *testing.T) {
+	t.Parallel()
+
+	show := `network.device_lan=device
+network.device_lan.name='br-lan'
+network.device_lan.type='bridge'
+network.device_lan.ports='eth0'
+network.lan=interface
+network.lan.device='br-lan'
+network.lan.proto='static'
+network.lan.ipaddr='192.168.10.1'
+network.lan.netmask='255.255.255.0'
+`
+	bundle := &netjsonconfig.Bundle{Packages: []netjsonconfig.Package{{Name: "main", Content: []byte(show)}}}
+	backend := openwrtbackend.New(ucirenderer.NewPlainTextRenderer(), ucirenderer.NewPlainTextParser())
+	parsed, err := backend.ToNetJSON(context.Background(), bundle, netjsonconfig.ParseOptions{})
+	if err != nil {
+		t.Fatalf("ToNetJSON failed: %v", err)
+	}
+	ifaces := parsed.(*openwrtv1.OpenWrtConfig).GetInterfaces()
+	if len(ifaces) != 1 {
+		t.Fatalf("expected 1 interface, got %d", len(ifaces))
+	}
+	if got := ifaces[0].GetBridgeMembers(); len(got) != 1 || got[0] != "eth0" {
+		t.Errorf("bridge members: got %v, want [eth0]", got)
+	}
+}
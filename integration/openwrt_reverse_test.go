@@ -0,0 +1,207 @@
+package integration
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"google.golang.org/protobuf/encoding/protojson"
+
+	openwrtv1 "github.com/honeybbq/netjson/gen/go/netjson/openwrt/v1"
+
+	openwrtbackend "github.com/honeybbq/netjsonconfig/backend/openwrt"
+	"github.com/honeybbq/netjsonconfig/pkg/netjsonconfig"
+	ucirenderer "github.com/honeybbq/netjsonconfig/pkg/renderer/uci"
+)
+
+// TestOpenWrtReverseRoundTrip renders NetJSON, parses the result back into NetJSON
+// and renders it again; the second rendering must match the golden file.
+func TestOpenWrtReverseRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	cases := []string{
+		"system_simple",
+		"system_full",
+		"system_leds",
+		"interface_bridge",
+		"routes_rules",
+		"switches",
+		"wireless",
+		"wireguard_interface",
+		"wireguard_peers",
+		"dns_openvpn",
+		"firewall",
+		"dhcp",
+		"zerotier",
+		"vxlan_wireguard",
+		"wan_protocols",
+		"virtual_devices",
+		"multi_address",
+		"wireless_enterprise",
+		"wireless_mesh",
+		"wireless_radios",
+		"custom_packages",
+		"system_timezone",
+		"openvpn_instances",
+	}
+
+	for _, name := range cases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			payload, err := os.ReadFile(filepath.Join("..", "testdata", "openwrt", name+".json"))
+			if err != nil {
+				t.Fatalf("read netjson: %v", err)
+			}
+			var device openwrtv1.OpenWrtConfig
+			if err := protojson.Unmarshal(payload, &device); err != nil {
+				t.Fatalf("unmarshal netjson: %v", err)
+			}
+
+			backend := openwrtbackend.New(ucirenderer.NewPlainTextRenderer(), ucirenderer.NewPlainTextParser())
+			bundle, err := backend.ToNative(context.Background(), &device, netjsonconfig.RenderOptions{})
+			if err != nil {
+				t.Fatalf("ToNative failed: %v", err)
+			}
+			parsed, err := backend.ToNetJSON(context.Background(), bundle, netjsonconfig.ParseOptions{})
+			if err != nil {
+				t.Fatalf("ToNetJSON failed: %v", err)
+			}
+			rendered, err := backend.ToNative(context.Background(), parsed, netjsonconfig.RenderOptions{})
+			if err != nil {
+				t.Fatalf("second ToNative failed: %v", err)
+			}
+
+			got := bundleToText(rendered)
+			wantBytes, err := os.ReadFile(filepath.Join("..", "testdata", "openwrt", name+".uci"))
+			if err != nil {
+				t.Fatalf("read expected: %v", err)
+			}
+			want := string(wantBytes)
+			if !compareConfigs(got, want) {
+				t.Fatalf("%s", formatConfigDiff(got, want))
+			}
+		})
+	}
+}
+
+// TestOpenWrtReverseWireless parses hand written wireless sections that do not follow
+// the renderer's own naming conventions.
+func TestOpenWrtReverseWireless(t *testing.T) {
+	t.Parallel()
+
+	content := `package wireless
+
+config wifi-device 'radio0'
+	option type 'mac80211'
+	option band '5g'
+	option channel '36'
+
+config wifi-iface 'wifi_guest'
+	option device 'radio0'
+	option mode 'ap'
+	option ssid 'Guest'
+	option encryption 'psk2+ccmp'
+	option key 'guestpassword'
+	list network 'guest'
+	list network 'iot'
+
+config wifi-iface
+	option device 'radio0'
+	option mode 'sta'
+	option ssid 'Uplink'
+	option network 'wwan'
+	option encryption 'sae'
+	option key 'uplinkpassword'
+`
+	bundle := &netjsonconfig.Bundle{
+		Packages: []netjsonconfig.Package{{Name: "wireless", Content: []byte(content)}},
+	}
+	backend := openwrtbackend.New(ucirenderer.NewPlainTextRenderer(), ucirenderer.NewPlainTextParser())
+	parsed, err := backend.ToNetJSON(context.Background(), bundle, netjsonconfig.ParseOptions{})
+	if err != nil {
+		t.Fatalf("ToNetJSON failed: %v", err)
+	}
+	device := parsed.(*openwrtv1.OpenWrtConfig)
+
+	if len(device.GetRadios()) != 1 || device.GetRadios()[0].GetChannel() != 36 {
+		t.Fatalf("unexpected radios: %v", device.GetRadios())
+	}
+	ifaces := device.GetInterfaces()
+	if len(ifaces) != 2 {
+		t.Fatalf("expected 2 wireless interfaces, got %d", len(ifaces))
+	}
+
+	guest := ifaces[0]
+	if guest.GetName() != "guest" {
+		t.Errorf("interface name: got %q, want %q", guest.GetName(), "guest")
+	}
+	if got := guest.GetWireless().GetMode(); got != "access_point" {
+		t.Errorf("mode: got %q, want access_point", got)
+	}
+	if got := guest.GetWireless().GetNetwork(); len(got) != 2 || got[0] != "guest" || got[1] != "iot" {
+		t.Errorf("network: got %v", got)
+	}
+	enc := guest.GetWireless().GetEncryption()
+	if enc.GetProtocol() != "wpa2_personal" || enc.GetCipher() != "ccmp" {
+		t.Errorf("encryption: got %q/%q", enc.GetProtocol(), enc.GetCipher())
+	}
+
+	uplink := ifaces[1]
+	if uplink.GetName() != "radio0_1" {
+		t.Errorf("anonymous interface name: got %q", uplink.GetName())
+	}
+	if got := uplink.GetWireless().GetMode(); got != "station" {
+		t.Errorf("mode: got %q, want station", got)
+	}
+	if got := uplink.GetWireless().GetNetwork(); len(got) != 1 || got[0] != "wwan" {
+		t.Errorf("network: got %v", got)
+	}
+	if got := uplink.GetWireless().GetEncryption().GetProtocol(); got != "wpa3_personal" {
+		t.Errorf("encryption: got %q, want wpa3_personal", got)
+	}
+}
+
+// TestOpenWrtReverseAnonymousSections keeps the generated names of anonymous sections
+// out of the NetJSON fields that carry section names.
+func TestOpenWrtReverseAnonymousSections(t *testing.T) {
+	t.Parallel()
+
+	system := `package system
+
+config system
+	option hostname 'router'
+
+config led
+	option sysfs 'green:power'
+	option trigger 'default-on'
+`
+	network := `package network
+
+config globals
+	option ula_prefix 'fd12:3456:789a::/48'
+`
+	bundle := &netjsonconfig.Bundle{
+		Packages: []netjsonconfig.Package{
+			{Name: "system", Content: []byte(system)},
+			{Name: "network", Content: []byte(network)},
+		},
+	}
+	backend := openwrtbackend.New(ucirenderer.NewPlainTextRenderer(), ucirenderer.NewPlainTextParser())
+	parsed, err := backend.ToNetJSON(context.Background(), bundle, netjsonconfig.ParseOptions{})
+	if err != nil {
+		t.Fatalf("ToNetJSON failed: %v", err)
+	}
+	device := parsed.(*openwrtv1.OpenWrtConfig)
+
+	if got := device.GetGeneral().GetGlobalsId(); got != "" {
+		t.Errorf("globals_id: got %q, want empty", got)
+	}
+	if got := device.GetGeneral().GetUlaPrefix(); got != "fd12:3456:789a::/48" {
+		t.Errorf("ula_prefix: got %q", got)
+	}
+	if len(device.GetLeds()) != 1 || device.GetLeds()[0].GetName() != "" || device.GetLeds()[0].GetSysfs() != "green:power" {
+		t.Fatalf("unexpected leds: %v", device.GetLeds())
+	}
+}
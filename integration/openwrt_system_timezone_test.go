@@ -0,0 +1,54 @@
+package integration
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"google.golang.org/protobuf/encoding/protojson"
+
+	openwrtv1 "github.com/honeybbq/netjson/gen/go/netjson/openwrt/v1"
+
+	openwrtbackend "github.com/honeybbq/netjsonconfig/backend/openwrt"
+	"github.com/honeybbq/netjsonconfig/pkg/netjsonconfig"
+	ucirenderer "github.com/honeybbq/netjsonconfig/pkg/renderer/uci"
+)
+
+// TestOpenWrtPosixTimezone keeps a POSIX timezone as is; the slash in its rules must
+// not be mistaken for a zone name.
+func TestOpenWrtPosixTimezone(t *testing.T) {
+	t.Parallel()
+
+	var device openwrtv1.OpenWrtConfig
+	payload := `{"general": {"timezone": "CET-1CEST,M3.5.0,M10.5.0/3", "zonename": "Europe/Rome"}}`
+	if err := protojson.Unmarshal([]byte(payload), &device); err != nil {
+		t.Fatalf("unmarshal netjson: %v", err)
+	}
+	backend := openwrtbackend.New(ucirenderer.NewPlainTextRenderer(), ucirenderer.NewPlainTextParser())
+	bundle, err := backend.ToNative(context.Background(), &device, netjsonconfig.RenderOptions{})
+	if err != nil {
+		t.Fatalf("ToNative failed: %v", err)
+	}
+	got := bundleToText(bundle)
+	for _, want := range []string{"option timezone 'CET-1CEST,M3.5.0,M10.5.0/3'", "option zonename 'Europe/Rome'"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("missing %s in:\n%s", want, got)
+		}
+	}
+}
+
+// TestOpenWrtSystemTimezone renders testdata/openwrt/system_timezone.json and compares it with system_timezone.uci.
+func TestOpenWrtSystemTimezone(t *testing.T) {
+	t.Parallel()
+	assertOpenWrtGolden(t, "system_timezone")
+}
+
+// TestOpenWrtSystemTimezoneValidation checks the timezone and zonename rules.
+func TestOpenWrtSystemTimezoneValidation(t *testing.T) {
+	t.Parallel()
+
+	assertOpenWrtValidation(t, map[string]openwrtValidationCase{
+		"unknown timezone": {payload: `{"general": {"timezone": "Europe/Atlantis"}}`, want: `unknown timezone "Europe/Atlantis"`},
+		"unknown zonename": {payload: `{"general": {"timezone": "CET-1CEST,M3.5.0,M10.5.0/3", "zonename": "Mars/Olympus"}}`, want: `unknown zonename "Mars/Olympus"`},
+	})
+}
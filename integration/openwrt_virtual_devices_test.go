@@ -0,0 +1,25 @@
+package integration
+
+import "testing"
+
+// TestOpenWrtVirtualDevices renders testdata/openwrt/virtual_devices.json and compares it with virtual_devices.uci.
+func TestOpenWrtVirtualDevices(t *testing.T) {
+	t.Parallel()
+	assertOpenWrtGolden(t, "virtual_devices")
+}
+
+// TestOpenWrtVirtualDevicesValidation checks the vlan, macvlan and veth device rules.
+func TestOpenWrtVirtualDevicesValidation(t *testing.T) {
+	t.Parallel()
+
+	assertOpenWrtValidation(t, map[string]openwrtValidationCase{
+		"vlan without parent":  {payload: `{"interfaces": [{"name": "v", "type": "8021q", "vlan": {"vid": 10}}]}`, want: `8021q requires the parent ifname`},
+		"vlan vid zero":        {payload: `{"interfaces": [{"name": "v", "type": "8021q", "vlan": {"ifname": "eth0"}}]}`, want: `vid 0 out of range 1-4094`},
+		"vlan vid too large":   {payload: `{"interfaces": [{"name": "v", "type": "8021ad", "vlan": {"ifname": "eth0", "vid": 4095}}]}`, want: `vid 4095 out of range 1-4094`},
+		"invalid qos mapping":  {payload: `{"interfaces": [{"name": "v", "type": "8021q", "vlan": {"ifname": "eth0", "vid": 10, "egress_qos_mapping": ["1-2"]}}]}`, want: `invalid qos mapping "1-2" (want <from>:<to>)`},
+		"macvlan invalid mode": {payload: `{"interfaces": [{"name": "m", "type": "macvlan", "macvlan": {"ifname": "eth0", "mode": "source"}}]}`, want: `invalid macvlan mode "source" (want private, vepa, bridge or passthru)`},
+		"veth without peer":    {payload: `{"interfaces": [{"name": "ns0", "type": "veth", "veth": {}}]}`, want: `veth requires peer_name`},
+		"device name too long": {payload: `{"interfaces": [{"name": "v", "type": "8021q", "vlan": {"ifname": "eth0-uplink-port", "vid": 10}}]}`, want: `device name "eth0-uplink-port.10" is longer than 15 characters`},
+		"duplicate device":     {payload: `{"interfaces": [{"name": "a", "type": "8021q", "vlan": {"ifname": "eth0", "vid": 10}}, {"name": "b", "type": "8021q", "vlan": {"ifname": "eth0", "vid": 10}}]}`, want: `device "eth0.10" already defined by "a"`},
+	})
+}
@@ -80,3 +80,52 @@ func TestOpenWrtVlanFiltering(t *testing.T) {
 		}
 	})
 }
+
+// TestOpenWrtVlanFilteringRoundTrip parses the rendered bridge-vlan sections back into
+// vlan_filtering and checks that rendering again yields the same configuration.
+func TestOpenWrtVlanFilteringRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	jsonData, err := os.ReadFile(filepath.Join("..", "testdata", "openwrt", "vlan_filtering.json"))
+	if err != nil {
+		t.Fatalf("failed to read test JSON: %v", err)
+	}
+	var cfg openwrtv1.OpenWrtConfig
+	if err := protojson.Unmarshal(jsonData, &cfg); err != nil {
+		t.Fatalf("failed to unmarshal JSON: %v", err)
+	}
+
+	backend := openwrtbackend.New(ucirenderer.NewPlainTextRenderer(), ucirenderer.NewPlainTextParser())
+	first, err := backend.ToNative(context.Background(), &cfg, netjsonconfig.RenderOptions{})
+	if err != nil {
+		t.Fatalf("render failed: %v", err)
+	}
+	parsed, err := backend.ToNetJSON(context.Background(), first, netjsonconfig.ParseOptions{})
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+
+	device, ok := parsed.(*openwrtv1.OpenWrtConfig)
+	if !ok {
+		t.Fatalf("unexpected message type %T", parsed)
+	}
+	if got := len(device.GetInterfaces()); got != 1 {
+		t.Fatalf("expected synthetic VLAN interfaces to be folded, got %d interfaces", got)
+	}
+	vlans := device.GetInterfaces()[0].GetVlanFiltering()
+	if len(vlans) != 2 || vlans[0].GetVlan() != 10 || vlans[1].GetVlan() != 20 {
+		t.Fatalf("unexpected vlan_filtering: %v", vlans)
+	}
+	primary := vlans[0].GetPorts()[1]
+	if primary.GetIfname() != "eth1" || primary.GetTagging() != "u" || !primary.GetPrimaryVid() {
+		t.Errorf("unexpected primary port: %v", primary)
+	}
+
+	second, err := backend.ToNative(context.Background(), parsed, netjsonconfig.RenderOptions{})
+	if err != nil {
+		t.Fatalf("second render failed: %v", err)
+	}
+	if got, want := bundleToText(second), bundleToText(first); got != want {
+		t.Fatalf("%s", formatConfigDiff(got, want))
+	}
+}
@@ -0,0 +1,24 @@
+package integration
+
+import "testing"
+
+// TestOpenWrtVxlanWireguard renders testdata/openwrt/vxlan_wireguard.json and compares it with vxlan_wireguard.uci.
+func TestOpenWrtVxlanWireguard(t *testing.T) {
+	t.Parallel()
+	assertOpenWrtGolden(t, "vxlan_wireguard")
+}
+
+// TestOpenWrtVxlanWireguardValidation checks the vxlan vtep, vni and tunlink rules.
+func TestOpenWrtVxlanWireguardValidation(t *testing.T) {
+	t.Parallel()
+
+	wg := `{"name": "wg0", "type": "wireguard", "wireguard": {"private_key": "key"}}`
+	assertOpenWrtValidation(t, map[string]openwrtValidationCase{
+		"missing vtep":          {payload: `{"interfaces": [{"name": "vx0", "type": "vxlan", "vxlan": {"vni": 1}}]}`, want: `vtep "" is not an IP address`},
+		"missing vni":           {payload: `{"interfaces": [{"name": "vx0", "type": "vxlan", "vxlan": {"vtep": "10.0.0.2"}}]}`, want: `vni or auto_vni is required`},
+		"vni out of range":      {payload: `{"interfaces": [{"name": "vx0", "type": "vxlan", "vxlan": {"vtep": "10.0.0.2", "vni": 16777216}}]}`, want: `vni 16777216 out of range 1-16777215`},
+		"unknown tunlink":       {payload: `{"interfaces": [{"name": "vx0", "type": "vxlan", "vxlan": {"vtep": "10.0.0.2", "vni": 1, "tunlink": "wg1"}}]}`, want: `tunlink references unknown interface "wg1"`},
+		"tunlink not wireguard": {payload: `{"interfaces": [{"name": "lan", "type": "ethernet", "proto": "dhcp"}, {"name": "vx0", "type": "vxlan", "vxlan": {"vtep": "10.0.0.2", "vni": 1, "tunlink": "lan"}}]}`, want: `tunlink "lan" is not a wireguard interface`},
+		"duplicate vni":         {payload: `{"interfaces": [` + wg + `, {"name": "vx0", "type": "vxlan", "vxlan": {"vtep": "10.0.0.2", "vni": 5}}, {"name": "vx1", "type": "vxlan", "vxlan": {"vtep": "10.0.0.3", "vni": 5}}]}`, want: `vni 5 on port 4789 already used by "vx0"`},
+	})
+}
@@ -0,0 +1,25 @@
+package integration
+
+import "testing"
+
+// TestOpenWrtWanProtocols renders testdata/openwrt/wan_protocols.json and compares it with wan_protocols.uci.
+func TestOpenWrtWanProtocols(t *testing.T) {
+	t.Parallel()
+	assertOpenWrtGolden(t, "wan_protocols")
+}
+
+// TestOpenWrtWanProtocolsValidation checks the pppoe, 6in4, gre, qmi and modemmanager rules.
+func TestOpenWrtWanProtocolsValidation(t *testing.T) {
+	t.Parallel()
+
+	assertOpenWrtValidation(t, map[string]openwrtValidationCase{
+		"pppoe without password": {payload: `{"interfaces": [{"name": "wan", "proto": "pppoe", "pppoe": {"username": "user"}}]}`, want: `pppoe username and password must be set together`},
+		"6in4 ipv6 peer":         {payload: `{"interfaces": [{"name": "he", "proto": "6in4", "tunnel6in4": {"peeraddr": "2001:db8::1"}}]}`, want: `6in4 peeraddr "2001:db8::1" is not an IPv4 address`},
+		"6in4 ipv4 prefix":       {payload: `{"interfaces": [{"name": "he", "proto": "6in4", "tunnel6in4": {"peeraddr": "192.0.2.1", "ip6prefix": ["10.0.0.0/8"]}}]}`, want: `6in4 address "10.0.0.0/8" is not an IPv6 prefix`},
+		"gre missing peer":       {payload: `{"interfaces": [{"name": "gre", "proto": "gre", "gre": {}}]}`, want: `gre address "" does not match the protocol family`},
+		"grev6 ipv4 peer":        {payload: `{"interfaces": [{"name": "gre", "proto": "grev6", "gre": {"peeraddr": "192.0.2.1"}}]}`, want: `grev6 address "192.0.2.1" does not match the protocol family`},
+		"qmi missing device":     {payload: `{"interfaces": [{"name": "lte", "proto": "qmi", "modem": {"apn": "internet"}}]}`, want: `qmi requires the modem device`},
+		"qmi invalid auth":       {payload: `{"interfaces": [{"name": "lte", "proto": "qmi", "modem": {"device": "/dev/cdc-wdm0", "auth": "mschap"}}]}`, want: `invalid qmi auth "mschap" (want none, pap, chap or both)`},
+		"modem invalid pin":      {payload: `{"interfaces": [{"name": "lte", "proto": "modemmanager", "modem": {"device": "/sys/x", "pincode": "12ab"}}]}`, want: `modemmanager pincode must be 4 to 8 digits`},
+	})
+}
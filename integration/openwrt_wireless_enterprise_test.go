@@ -0,0 +1,26 @@
+package integration
+
+import "testing"
+
+// TestOpenWrtWirelessEnterprise renders testdata/openwrt/wireless_enterprise.json and compares it with wireless_enterprise.uci.
+func TestOpenWrtWirelessEnterprise(t *testing.T) {
+	t.Parallel()
+	assertOpenWrtGolden(t, "wireless_enterprise")
+}
+
+// TestOpenWrtWirelessEnterpriseValidation checks the wireless encryption rules.
+func TestOpenWrtWirelessEnterpriseValidation(t *testing.T) {
+	t.Parallel()
+
+	assertOpenWrtValidation(t, map[string]openwrtValidationCase{
+		"unknown protocol":              {payload: `{"interfaces": [{"name": "w", "type": "wireless", "wireless": {"radio": "radio0", "mode": "access_point", "ssid": "x", "encryption": {"protocol": "wpa2_enterprise_eap"}}}]}`, want: `unsupported encryption protocol "wpa2_enterprise_eap"`},
+		"short psk":                     {payload: `{"interfaces": [{"name": "w", "type": "wireless", "wireless": {"radio": "radio0", "mode": "access_point", "ssid": "x", "encryption": {"protocol": "wpa2_personal", "key": "short"}}}]}`, want: `wpa2_personal key must be 8 to 63 characters or 64 hex digits`},
+		"sae without key":               {payload: `{"interfaces": [{"name": "w", "type": "wireless", "wireless": {"radio": "radio0", "mode": "access_point", "ssid": "x", "encryption": {"protocol": "wpa3_personal"}}}]}`, want: `wpa3_personal requires a key`},
+		"enterprise ap without server":  {payload: `{"interfaces": [{"name": "w", "type": "wireless", "wireless": {"radio": "radio0", "mode": "access_point", "ssid": "x", "encryption": {"protocol": "wpa2_enterprise", "auth_secret": "s"}}}]}`, want: `wpa2_enterprise requires server and auth_secret`},
+		"enterprise ap without secret":  {payload: `{"interfaces": [{"name": "w", "type": "wireless", "wireless": {"radio": "radio0", "mode": "access_point", "ssid": "x", "encryption": {"protocol": "wpa3_enterprise", "server": "10.0.0.1"}}}]}`, want: `wpa3_enterprise requires server and auth_secret`},
+		"invalid dynamic vlan":          {payload: `{"interfaces": [{"name": "w", "type": "wireless", "wireless": {"radio": "radio0", "mode": "access_point", "ssid": "x", "encryption": {"protocol": "wpa2_enterprise", "server": "10.0.0.1", "auth_secret": "s", "dynamic_vlan": 3}}}]}`, want: `dynamic_vlan must be 0, 1 or 2`},
+		"station without eap type":      {payload: `{"interfaces": [{"name": "w", "type": "wireless", "wireless": {"radio": "radio0", "mode": "station", "ssid": "x", "encryption": {"protocol": "wpa2_enterprise", "identity": "u", "password": "p"}}}]}`, want: `wpa2_enterprise station requires eap_type`},
+		"station tls without key":       {payload: `{"interfaces": [{"name": "w", "type": "wireless", "wireless": {"radio": "radio0", "mode": "station", "ssid": "x", "encryption": {"protocol": "wpa2_enterprise", "eap_type": "tls", "client_cert": "/etc/c.pem"}}}]}`, want: `eap tls requires client_cert and priv_key`},
+		"station peap without password": {payload: `{"interfaces": [{"name": "w", "type": "wireless", "wireless": {"radio": "radio0", "mode": "station", "ssid": "x", "encryption": {"protocol": "wpa2_enterprise", "eap_type": "peap", "identity": "u"}}}]}`, want: `eap peap requires identity and password`},
+	})
+}
@@ -0,0 +1,25 @@
+package integration
+
+import "testing"
+
+// TestOpenWrtWirelessMesh renders testdata/openwrt/wireless_mesh.json and compares it with wireless_mesh.uci.
+func TestOpenWrtWirelessMesh(t *testing.T) {
+	t.Parallel()
+	assertOpenWrtGolden(t, "wireless_mesh")
+}
+
+// TestOpenWrtWirelessMeshValidation checks the 802.11s mesh and wds rules.
+func TestOpenWrtWirelessMeshValidation(t *testing.T) {
+	t.Parallel()
+
+	assertOpenWrtValidation(t, map[string]openwrtValidationCase{
+		"mesh without id":          {payload: `{"radios": [{"name": "radio0", "band": "5g", "channel": 36}], "interfaces": [{"name": "w", "type": "wireless", "wireless": {"radio": "radio0", "mode": "802.11s"}}]}`, want: `mesh requires mesh_id`},
+		"mesh on auto channel":     {payload: `{"radios": [{"name": "radio0", "band": "5g", "channel": 0}], "interfaces": [{"name": "w", "type": "wireless", "wireless": {"radio": "radio0", "mode": "802.11s", "mesh_id": "m"}}]}`, want: `mesh radio "radio0" needs a fixed channel`},
+		"mesh on unknown radio":    {payload: `{"radios": [{"name": "radio0", "band": "5g", "channel": 36}], "interfaces": [{"name": "w", "type": "wireless", "wireless": {"radio": "radio9", "mode": "802.11s", "mesh_id": "m"}}]}`, want: `mesh radio "radio9" is not defined`},
+		"mesh with psk":            {payload: `{"radios": [{"name": "radio0", "band": "5g", "channel": 36}], "interfaces": [{"name": "w", "type": "wireless", "wireless": {"radio": "radio0", "mode": "802.11s", "mesh_id": "m", "encryption": {"protocol": "wpa2_personal", "key": "12345678"}}}]}`, want: `mesh encryption must be none or wpa3_personal, got "wpa2_personal"`},
+		"mesh rssi out of range":   {payload: `{"radios": [{"name": "radio0", "band": "5g", "channel": 36}], "interfaces": [{"name": "w", "type": "wireless", "wireless": {"radio": "radio0", "mode": "802.11s", "mesh_id": "m", "mesh_rssi_threshold": 10}}]}`, want: `mesh_rssi_threshold 10 out of range -100-0`},
+		"mesh options on ap":       {payload: `{"radios": [{"name": "radio0", "band": "5g", "channel": 36}], "interfaces": [{"name": "w", "type": "wireless", "wireless": {"radio": "radio0", "mode": "access_point", "ssid": "x", "mesh_id": "m"}}]}`, want: `mesh options require mode 802.11s, got "access_point"`},
+		"wds station without ssid": {payload: `{"radios": [{"name": "radio0", "band": "5g", "channel": 36}], "interfaces": [{"name": "w", "type": "wireless", "wireless": {"radio": "radio0", "mode": "station", "wds": true}}]}`, want: `wds station needs the ssid or bssid of its access point`},
+		"wds on mesh":              {payload: `{"radios": [{"name": "radio0", "band": "5g", "channel": 36}], "interfaces": [{"name": "w", "type": "wireless", "wireless": {"radio": "radio0", "mode": "802.11s", "mesh_id": "m", "wds": true}}]}`, want: `wds is only supported in access_point and station mode`},
+	})
+}
@@ -0,0 +1,30 @@
+package integration
+
+import "testing"
+
+// TestOpenWrtWirelessRadios renders testdata/openwrt/wireless_radios.json and compares it with wireless_radios.uci.
+func TestOpenWrtWirelessRadios(t *testing.T) {
+	t.Parallel()
+	assertOpenWrtGolden(t, "wireless_radios")
+}
+
+// TestOpenWrtWirelessRadiosValidation checks the band, htmode and channel rules of radios.
+func TestOpenWrtWirelessRadiosValidation(t *testing.T) {
+	t.Parallel()
+
+	assertOpenWrtValidation(t, map[string]openwrtValidationCase{
+		"unknown band":                {payload: `{"radios": [{"name": "radio0", "band": "3g", "channel": 1}]}`, want: `invalid band "3g" (want 2g, 5g, 6g or 60g)`},
+		"unknown htmode":              {payload: `{"radios": [{"name": "radio0", "band": "5g", "channel": 36, "htmode": "VHT60"}]}`, want: `unknown htmode "VHT60"`},
+		"vht on 2g":                   {payload: `{"radios": [{"name": "radio0", "band": "2g", "channel": 6, "htmode": "VHT20"}]}`, want: `htmode VHT20 is not valid on band 2g`},
+		"ht on 6g":                    {payload: `{"radios": [{"name": "radio0", "band": "6g", "channel": 5, "htmode": "HT20"}]}`, want: `htmode HT20 is not valid on band 6g`},
+		"320 MHz on 5g":               {payload: `{"radios": [{"name": "radio0", "band": "5g", "channel": 36, "htmode": "EHT320"}]}`, want: `htmode EHT320 is not valid on band 5g`},
+		"2g channel on 5g":            {payload: `{"radios": [{"name": "radio0", "band": "5g", "channel": 6}]}`, want: `channel 6 is not valid on band 5g`},
+		"5g channel off grid":         {payload: `{"radios": [{"name": "radio0", "band": "5g", "channel": 38}]}`, want: `channel 38 is not valid on band 5g`},
+		"6g channel off grid":         {payload: `{"radios": [{"name": "radio0", "band": "6g", "channel": 4}]}`, want: `channel 4 is not valid on band 6g`},
+		"2g channel 15":               {payload: `{"radios": [{"name": "radio0", "band": "2g", "channel": 15}]}`, want: `channel 15 is not valid on band 2g`},
+		"channels with fixed channel": {payload: `{"radios": [{"name": "radio0", "band": "5g", "channel": 36, "channels": [36, 40]}]}`, want: `channels requires channel auto`},
+		"illegal channels entry":      {payload: `{"radios": [{"name": "radio0", "band": "5g", "channels": [36, 37]}]}`, want: `channels entry 37 is not valid on band 5g`},
+		"legacy 6g band":              {payload: `{"radios": [{"name": "radio0", "band": "6g", "channel": 5}]}`, legacy: true, want: `band 6g requires OpenWrt 21.02 or later`},
+		"legacy he htmode":            {payload: `{"radios": [{"name": "radio0", "band": "5g", "channel": 36, "htmode": "HE80"}]}`, legacy: true, want: `htmode HE80 requires OpenWrt 21.02 or later`},
+	})
+}
@@ -0,0 +1,88 @@
+package integration
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	openwrtv1 "github.com/honeybbq/netjson/gen/go/netjson/openwrt/v1"
+
+	openwrtbackend "github.com/honeybbq/netjsonconfig/backend/openwrt"
+	"github.com/honeybbq/netjsonconfig/pkg/netjsonconfig"
+	ucirenderer "github.com/honeybbq/netjsonconfig/pkg/renderer/uci"
+)
+
+// TestOpenWrtZerotierLocalConf checks the local.conf file generated from local_conf
+// and that parsing folds it back; the UCI output is covered by TestOpenWrtRendering.
+func TestOpenWrtZerotierLocalConf(t *testing.T) {
+	t.Parallel()
+
+	payload, err := os.ReadFile(filepath.Join("..", "testdata", "openwrt", "zerotier.json"))
+	if err != nil {
+		t.Fatalf("read netjson: %v", err)
+	}
+	var device openwrtv1.OpenWrtConfig
+	if err := protojson.Unmarshal(payload, &device); err != nil {
+		t.Fatalf("unmarshal netjson: %v", err)
+	}
+
+	backend := openwrtbackend.New(ucirenderer.NewPlainTextRenderer(), ucirenderer.NewPlainTextParser())
+	bundle, err := backend.ToNative(context.Background(), &device, netjsonconfig.RenderOptions{})
+	if err != nil {
+		t.Fatalf("ToNative failed: %v", err)
+	}
+
+	if len(bundle.Files) != 1 || bundle.Files[0].Path != "/etc/openwisp/zerotier/zerotier.conf" {
+		t.Fatalf("expected local.conf file, got %+v", bundle.Files)
+	}
+	wantConf := `{
+    "settings": {
+        "primaryPort": 9993,
+        "portMappingEnabled": true,
+        "allowTcpFallbackRelay": false,
+        "interfacePrefixBlacklist": [
+            "eth1"
+        ]
+    }
+}
+`
+	if got := string(bundle.Files[0].Content); got != wantConf {
+		t.Fatalf("local.conf mismatch\n--- got ---\n%s\n--- want ---\n%s", got, wantConf)
+	}
+
+	// local.conf is folded back into local_conf instead of staying a plain file
+	parsed, err := backend.ToNetJSON(context.Background(), bundle, netjsonconfig.ParseOptions{})
+	if err != nil {
+		t.Fatalf("ToNetJSON failed: %v", err)
+	}
+	cfg := parsed.(*openwrtv1.OpenWrtConfig)
+	if len(cfg.GetFiles()) != 0 {
+		t.Fatalf("unexpected files: %v", cfg.GetFiles())
+	}
+	zt := cfg.GetZerotier()[0]
+	if !proto.Equal(zt.GetLocalConf(), device.GetZerotier()[0].GetLocalConf()) {
+		t.Fatalf("local_conf mismatch: got %v", zt.GetLocalConf())
+	}
+	if len(zt.GetNetworks()) != 2 || zt.GetNetworks()[0].GetIfname() != "owzt_office" || zt.GetNetworks()[1].GetIfname() != "" {
+		t.Fatalf("unexpected networks: %v", zt.GetNetworks())
+	}
+}
+
+// TestOpenWrtZerotier renders testdata/openwrt/zerotier.json and compares it with zerotier.uci.
+func TestOpenWrtZerotier(t *testing.T) {
+	t.Parallel()
+	assertOpenWrtGolden(t, "zerotier")
+}
+
+// TestOpenWrtZerotierValidation checks the zerotier network id rule.
+func TestOpenWrtZerotierValidation(t *testing.T) {
+	t.Parallel()
+
+	assertOpenWrtValidation(t, map[string]openwrtValidationCase{
+		"invalid network id": {payload: `{"zerotier": [{"name": "global", "networks": [{"id": "not-a-network"}]}]}`, want: `network #1 has invalid id "not-a-network" (want 16 hex digits)`},
+	})
+}
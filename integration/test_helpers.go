@@ -1,10 +1,22 @@
 package integration
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
+	"testing"
 
+	"google.golang.org/protobuf/encoding/protojson"
+
+	openwrtv1 "github.com/honeybbq/netjson/gen/go/netjson/openwrt/v1"
+
+	openwrtbackend "github.com/honeybbq/netjsonconfig/backend/openwrt"
 	"github.com/honeybbq/netjsonconfig/pkg/netjsonconfig"
+	"github.com/honeybbq/netjsonconfig/pkg/nxerrors"
+	ucirenderer "github.com/honeybbq/netjsonconfig/pkg/renderer/uci"
 )
 
 // bundleToText 将 Bundle 转换为文本格式（用于测试对比）
@@ -91,3 +103,70 @@ func formatConfigDiff(got, want string) string {
 
 	return b.String()
 }
+
+// assertOpenWrtGolden 渲染 testdata/openwrt/<name>.json 并与 <name>.uci 对比
+func assertOpenWrtGolden(t *testing.T, name string) {
+	t.Helper()
+
+	payload, err := os.ReadFile(filepath.Join("..", "testdata", "openwrt", name+".json"))
+	if err != nil {
+		t.Fatalf("read netjson: %v", err)
+	}
+	var device openwrtv1.OpenWrtConfig
+	if err := protojson.Unmarshal(payload, &device); err != nil {
+		t.Fatalf("unmarshal netjson: %v", err)
+	}
+
+	backend := openwrtbackend.New(ucirenderer.NewPlainTextRenderer(), ucirenderer.NewPlainTextParser())
+	bundle, err := backend.ToNative(context.Background(), &device, netjsonconfig.RenderOptions{})
+	if err != nil {
+		t.Fatalf("ToNative failed: %v", err)
+	}
+
+	got := bundleToText(bundle)
+	wantBytes, err := os.ReadFile(filepath.Join("..", "testdata", "openwrt", name+".uci"))
+	if err != nil {
+		t.Fatalf("read expected: %v", err)
+	}
+	want := string(wantBytes)
+	if !compareConfigs(got, want) {
+		t.Fatalf("%s", formatConfigDiff(got, want))
+	}
+}
+
+// openwrtValidationCase 描述一个无效的 NetJSON 输入及其校验错误应包含的文本，
+// 避免用例因输入中无关的错误而通过
+type openwrtValidationCase struct {
+	payload string
+	legacy  bool
+	want    string
+}
+
+// assertOpenWrtValidation 逐个渲染用例，要求返回提及 want 的校验错误
+func assertOpenWrtValidation(t *testing.T, cases map[string]openwrtValidationCase) {
+	t.Helper()
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var device openwrtv1.OpenWrtConfig
+			if err := protojson.Unmarshal([]byte(tc.payload), &device); err != nil {
+				t.Fatalf("unmarshal netjson: %v", err)
+			}
+			opts := netjsonconfig.RenderOptions{}
+			if tc.legacy {
+				opts.Mode = netjsonconfig.RenderModeLegacy
+			}
+			backend := openwrtbackend.New(ucirenderer.NewPlainTextRenderer(), ucirenderer.NewPlainTextParser())
+			_, err := backend.ToNative(context.Background(), &device, opts)
+			var nxErr *nxerrors.Error
+			if !errors.As(err, &nxErr) || nxErr.Kind != nxerrors.KindValidation {
+				t.Fatalf("expected validation error, got %v", err)
+			}
+			if !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("error %q should mention %q", err, tc.want)
+			}
+		})
+	}
+}
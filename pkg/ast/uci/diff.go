@@ -0,0 +1,215 @@
+package uci
+
+import "slices"
+
+// ChangeKind 描述 section 或 option 的变更类型。
+type ChangeKind int
+
+const (
+	ChangeAdded ChangeKind = iota + 1
+	ChangeModified
+	ChangeDeleted
+)
+
+func (k ChangeKind) String() string {
+	switch k {
+	case ChangeAdded:
+		return "added"
+	case ChangeModified:
+		return "modified"
+	case ChangeDeleted:
+		return "deleted"
+	default:
+		return "unknown"
+	}
+}
+
+// Diff 是两个 Document 之间的差异，由 Compare 生成。
+type Diff struct {
+	// Sections 按包分组；同一包内依次为修改、删除、新增的 section。
+	Sections []SectionChange
+}
+
+// SectionChange 描述单个 section 的变更。
+type SectionChange struct {
+	Kind    ChangeKind
+	Package string
+	// Current 为部署中的 section（新增时为 nil），Desired 为目标 section（删除时为 nil）。
+	Current *Section
+	Desired *Section
+	// Index 是 Current 在同类型 section 中的序号，用于引用匿名 section（@type[index]）；
+	// 新增时为 -1。
+	Index int
+	// Options 仅在 ChangeModified 时填充。
+	Options []OptionChange
+}
+
+// Name 返回 section 名称。
+func (c SectionChange) Name() string {
+	if c.Desired != nil {
+		return c.Desired.Name
+	}
+	return c.Current.Name
+}
+
+// Type 返回 section 类型。
+func (c SectionChange) Type() string {
+	if c.Desired != nil {
+		return c.Desired.Type
+	}
+	return c.Current.Type
+}
+
+// OptionChange 描述单个 option 或 list 的变更。
+type OptionChange struct {
+	Kind ChangeKind
+	Key  string
+	// CurrentList/DesiredList 表示该键在两侧是否为 list，Old/New 为对应的值。
+	CurrentList bool
+	DesiredList bool
+	Old         []string
+	New         []string
+}
+
+// Empty 报告两个文档是否没有差异。
+func (d *Diff) Empty() bool {
+	return d == nil || len(d.Sections) == 0
+}
+
+// Packages 返回受影响的包名，按首次出现的顺序。
+func (d *Diff) Packages() []string {
+	if d == nil {
+		return nil
+	}
+	var names []string
+	for _, change := range d.Sections {
+		if !slices.Contains(names, change.Package) {
+			names = append(names, change.Package)
+		}
+	}
+	return names
+}
+
+// Compare 计算从 current 变为 desired 所需的变更。
+//
+// section 按包名与 section 名称匹配，类型不同视为删除后重新创建。
+// 只比较 desired 中出现的包：未出现的包视为不受管理，保持原样；
+// 需要清空某个包时应在 desired 中保留该包的空 Package。
+// 注释、位置与 section 顺序不参与比较。
+func Compare(current, desired *Document) *Diff {
+	diff := &Diff{}
+	if desired == nil {
+		return diff
+	}
+	for _, want := range desired.Packages {
+		if want == nil {
+			continue
+		}
+		diff.Sections = append(diff.Sections, comparePackage(findPackage(current, want.Name), want)...)
+	}
+	return diff
+}
+
+func findPackage(doc *Document, name string) *Package {
+	if doc == nil {
+		return nil
+	}
+	for _, pkg := range doc.Packages {
+		if pkg != nil && pkg.Name == name {
+			return pkg
+		}
+	}
+	return nil
+}
+
+func comparePackage(current, desired *Package) []SectionChange {
+	wanted := make(map[string]*Section, len(desired.Sections))
+	for _, section := range desired.Sections {
+		if section != nil {
+			wanted[section.Name] = section
+		}
+	}
+
+	var modified, deleted, added []SectionChange
+	matched := make(map[string]struct{})
+	if current != nil {
+		counters := make(map[string]int)
+		for _, section := range current.Sections {
+			if section == nil {
+				continue
+			}
+			index := counters[section.Type]
+			counters[section.Type]++
+
+			want, ok := wanted[section.Name]
+			if !ok || want.Type != section.Type {
+				deleted = append(deleted, SectionChange{Kind: ChangeDeleted, Package: desired.Name, Current: section, Index: index})
+				continue
+			}
+			matched[section.Name] = struct{}{}
+			if options := compareEntries(section, want); len(options) > 0 {
+				modified = append(modified, SectionChange{Kind: ChangeModified, Package: desired.Name, Current: section, Desired: want, Index: index, Options: options})
+			}
+		}
+	}
+	for _, section := range desired.Sections {
+		if section == nil {
+			continue
+		}
+		if _, ok := matched[section.Name]; !ok {
+			added = append(added, SectionChange{Kind: ChangeAdded, Package: desired.Name, Desired: section, Index: -1})
+		}
+	}
+
+	return slices.Concat(modified, deleted, added)
+}
+
+// compareEntries 按键比较两个 section，键的顺序为 desired 中的顺序，其后是仅存在于 current 的键。
+func compareEntries(current, desired *Section) []OptionChange {
+	var changes []OptionChange
+	for _, key := range entryKeys(desired, current) {
+		oldList, oldValues := entryValues(current, key)
+		newList, newValues := entryValues(desired, key)
+		change := OptionChange{Key: key, CurrentList: oldList, DesiredList: newList, Old: oldValues, New: newValues}
+		switch {
+		case len(oldValues) == 0 && len(newValues) == 0:
+			continue
+		case len(oldValues) == 0:
+			change.Kind = ChangeAdded
+		case len(newValues) == 0:
+			change.Kind = ChangeDeleted
+		case oldList != newList || !slices.Equal(oldValues, newValues):
+			change.Kind = ChangeModified
+		default:
+			continue
+		}
+		changes = append(changes, change)
+	}
+	return changes
+}
+
+func entryKeys(sections ...*Section) []string {
+	var keys []string
+	seen := make(map[string]struct{})
+	for _, section := range sections {
+		for _, e := range section.Entries {
+			if _, ok := seen[e.Key]; ok {
+				continue
+			}
+			seen[e.Key] = struct{}{}
+			keys = append(keys, e.Key)
+		}
+	}
+	return keys
+}
+
+// entryValues 返回键的值；同一键同时存在 option 与 list 时以 list 为准。
+func entryValues(section *Section, key string) (bool, []string) {
+	if values := section.List(key); len(values) > 0 {
+		return true, values
+	}
+	if value, ok := section.Option(key); ok {
+		return false, []string{value}
+	}
+	return false, nil
+}
@@ -1,6 +1,10 @@
 package uci
 
-import commonv1 "github.com/honeybbq/netjson/gen/go/netjson/common/v1"
+import (
+	"fmt"
+
+	commonv1 "github.com/honeybbq/netjson/gen/go/netjson/common/v1"
+)
 
 // Document 表示完整的 UCI 配置集合。
 type Document struct {
@@ -12,22 +16,61 @@ type Document struct {
 type Package struct {
 	Name     string
 	Sections []*Section
+	// Comments 保存最后一个 section 之后的注释。
+	Comments []Comment
 }
 
 // Section 是最小 AST 节点。
+//
+// option 与 list 按出现顺序保存在 Entries 中，同名 option/list 可以交错出现；
+// builder 通过 Option/SetOption/List/SetList 等访问器以 map 的方式读写。
 type Section struct {
 	Type    string
 	Name    string
-	Options map[string][]string
-	Lists   map[string][]string
+	Entries []Entry
+	// Anonymous 表示源文件中该 section 未命名（config rule / @rule[0]），
+	// Name 为解析器按 <type>_<index> 生成的稳定名称。
+	Anonymous bool
+	// Ordered 为 true 时渲染器按 Entries 的顺序输出（解析得到的 section），
+	// 否则按 option、list 分组并按名称排序（builder 生成的 section）。
+	Ordered bool
+
+	// 以下字段仅由解析器填充，builder 生成的 section 保持零值。
+
+	// Pos 是 config 语句的位置。
+	Pos Position
+	// Comments 是紧邻 config 语句之前的注释。
+	Comments []Comment
+}
+
+// Position 描述源文件中的位置，行列均从 1 开始；零值表示未知。
+type Position struct {
+	Line   int
+	Column int
+}
+
+// IsValid 报告位置是否已知。
+func (p Position) IsValid() bool {
+	return p.Line > 0
+}
+
+func (p Position) String() string {
+	if !p.IsValid() {
+		return "-"
+	}
+	return fmt.Sprintf("%d:%d", p.Line, p.Column)
+}
+
+// Comment 是一行 UCI 注释，Text 不含前导 '#'（保留其后的空白）。
+type Comment struct {
+	Text string
+	Pos  Position
 }
 
-// NewSection 创建 Section 并初始化内部 map。
+// NewSection 创建空 Section。
 func NewSection(typ, name string) *Section {
 	return &Section{
-		Type:    typ,
-		Name:    name,
-		Options: make(map[string][]string),
-		Lists:   make(map[string][]string),
+		Type: typ,
+		Name: name,
 	}
 }
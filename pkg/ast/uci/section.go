@@ -0,0 +1,170 @@
+package uci
+
+import "sort"
+
+// EntryKind 区分 option 与 list 条目。
+type EntryKind int
+
+const (
+	// KindOption 对应 `option key 'value'`。
+	KindOption EntryKind = iota
+	// KindList 对应 `list key 'value'`，每个值一个条目。
+	KindList
+)
+
+// Entry 是 section 中的一行 option 或 list。
+type Entry struct {
+	Kind  EntryKind
+	Key   string
+	Value string
+	// Pos 与 Comments 仅由解析器填充。
+	Pos      Position
+	Comments []Comment
+}
+
+// Option 返回 option 的值；同名 option 出现多次时以最后一次为准。
+func (s *Section) Option(key string) (string, bool) {
+	if s == nil {
+		return "", false
+	}
+	for i := len(s.Entries) - 1; i >= 0; i-- {
+		if e := s.Entries[i]; e.Kind == KindOption && e.Key == key {
+			return e.Value, true
+		}
+	}
+	return "", false
+}
+
+// HasOption 报告 option 是否存在。
+func (s *Section) HasOption(key string) bool {
+	_, ok := s.Option(key)
+	return ok
+}
+
+// SetOption 设置 option：已存在时原地替换（保留位置与注释），否则追加到末尾。
+func (s *Section) SetOption(key, value string) {
+	replaced := false
+	entries := s.Entries[:0]
+	for _, e := range s.Entries {
+		if e.Kind == KindOption && e.Key == key {
+			if replaced {
+				continue
+			}
+			e.Value = value
+			replaced = true
+		}
+		entries = append(entries, e)
+	}
+	s.Entries = entries
+	if !replaced {
+		s.Entries = append(s.Entries, Entry{Kind: KindOption, Key: key, Value: value})
+	}
+}
+
+// List 返回 list 的全部值。
+func (s *Section) List(key string) []string {
+	if s == nil {
+		return nil
+	}
+	var values []string
+	for _, e := range s.Entries {
+		if e.Kind == KindList && e.Key == key {
+			values = append(values, e.Value)
+		}
+	}
+	return values
+}
+
+// AddList 向 list 追加一个值。
+func (s *Section) AddList(key, value string) {
+	s.Entries = append(s.Entries, Entry{Kind: KindList, Key: key, Value: value})
+}
+
+// SetList 替换 list 的全部值。新值放在原 list 首个条目的位置，
+// 原先不存在时追加到末尾；values 为空时等价于删除。
+func (s *Section) SetList(key string, values []string) {
+	at := -1
+	entries := make([]Entry, 0, len(s.Entries)+len(values))
+	for _, e := range s.Entries {
+		if e.Kind == KindList && e.Key == key {
+			if at < 0 {
+				at = len(entries)
+				for _, value := range values {
+					entries = append(entries, Entry{Kind: KindList, Key: key, Value: value, Pos: e.Pos, Comments: e.Comments})
+					e.Pos, e.Comments = Position{}, nil
+				}
+			}
+			continue
+		}
+		entries = append(entries, e)
+	}
+	if at < 0 {
+		for _, value := range values {
+			entries = append(entries, Entry{Kind: KindList, Key: key, Value: value})
+		}
+	}
+	s.Entries = entries
+}
+
+// Delete 删除同名的 option 与 list 条目。
+func (s *Section) Delete(key string) {
+	entries := s.Entries[:0]
+	for _, e := range s.Entries {
+		if e.Key != key {
+			entries = append(entries, e)
+		}
+	}
+	s.Entries = entries
+}
+
+// OptionKeys 按首次出现顺序返回 option 名。
+func (s *Section) OptionKeys() []string {
+	return s.keys(KindOption)
+}
+
+// ListKeys 按首次出现顺序返回 list 名。
+func (s *Section) ListKeys() []string {
+	return s.keys(KindList)
+}
+
+func (s *Section) keys(kind EntryKind) []string {
+	if s == nil {
+		return nil
+	}
+	var keys []string
+	seen := make(map[string]struct{})
+	for _, e := range s.Entries {
+		if e.Kind != kind {
+			continue
+		}
+		if _, ok := seen[e.Key]; ok {
+			continue
+		}
+		seen[e.Key] = struct{}{}
+		keys = append(keys, e.Key)
+	}
+	return keys
+}
+
+// Position 返回 option/list 首个条目的位置；未知时返回 section 自身的位置。
+func (s *Section) Position(key string) Position {
+	for _, e := range s.Entries {
+		if e.Key == key && e.Pos.IsValid() {
+			return e.Pos
+		}
+	}
+	return s.Pos
+}
+
+// SortedEntries 返回 builder 风格的条目顺序：先 option 后 list，各自按名称排序，
+// 同名条目保持原有相对顺序。
+func (s *Section) SortedEntries() []Entry {
+	entries := append([]Entry(nil), s.Entries...)
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].Kind != entries[j].Kind {
+			return entries[i].Kind < entries[j].Kind
+		}
+		return entries[i].Key < entries[j].Key
+	})
+	return entries
+}
@@ -0,0 +1,134 @@
+package netjsonconfig
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"strings"
+)
+
+// DefaultConfigDir is the location of UCI packages relative to the root filesystem.
+const DefaultConfigDir = "etc/config"
+
+// DefaultExtraFiles lists the glob patterns (relative to the root) collected as
+// additional files by LoadBundleFS when LoadOptions.Files is nil.
+// They cover the files NetJSON usually references from UCI: certificates, keys and scripts.
+var DefaultExtraFiles = []string{
+	"etc/openvpn/*",
+	"etc/wireguard/*",
+	"etc/zerotier/*",
+	"etc/crontabs/*",
+	"etc/dropbear/authorized_keys",
+	"etc/rc.local",
+}
+
+// LoadOptions controls how a root filesystem is turned into a Bundle.
+type LoadOptions struct {
+	ConfigDir string   // Directory holding UCI packages (default: DefaultConfigDir)
+	Files     []string // Glob patterns of additional files (nil: DefaultExtraFiles, empty: none)
+}
+
+// LoadBundleFS builds a Bundle from a router root filesystem, e.g. an extracted
+// sysupgrade backup or a rootfs copy.
+//
+// Every regular file directly under ConfigDir becomes a Package named after the file;
+// hidden files and sub directories are ignored. Files matching the Files patterns are
+// attached as additional files with absolute paths ("/etc/openvpn/ca.crt").
+func LoadBundleFS(fsys fs.FS, opts LoadOptions) (*Bundle, error) {
+	configDir := opts.ConfigDir
+	if configDir == "" {
+		configDir = DefaultConfigDir
+	}
+	configDir = strings.Trim(path.Clean(configDir), "/")
+
+	entries, err := fs.ReadDir(fsys, configDir)
+	if err != nil {
+		return nil, fmt.Errorf("read config dir %q: %w", configDir, err)
+	}
+
+	bundle := NewBundle("uci", "")
+	for _, entry := range entries {
+		name := entry.Name()
+		if strings.HasPrefix(name, ".") || !entry.Type().IsRegular() {
+			continue
+		}
+		content, err := fs.ReadFile(fsys, path.Join(configDir, name))
+		if err != nil {
+			return nil, fmt.Errorf("read package %q: %w", name, err)
+		}
+		bundle.Packages = append(bundle.Packages, Package{Name: name, Content: content})
+	}
+	if len(bundle.Packages) == 0 {
+		return nil, fmt.Errorf("no uci packages found in %q", configDir)
+	}
+
+	patterns := opts.Files
+	if patterns == nil {
+		patterns = DefaultExtraFiles
+	}
+	seen := make(map[string]struct{})
+	for _, pattern := range patterns {
+		matches, err := fs.Glob(fsys, strings.TrimPrefix(pattern, "/"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid file pattern %q: %w", pattern, err)
+		}
+		for _, match := range matches {
+			if _, ok := seen[match]; ok || path.Dir(match) == configDir {
+				continue
+			}
+			seen[match] = struct{}{}
+
+			file, err := loadFile(fsys, match)
+			if err != nil {
+				return nil, err
+			}
+			if file != nil {
+				bundle.Files = append(bundle.Files, *file)
+			}
+		}
+	}
+
+	return bundle, nil
+}
+
+// LoadBundleDir is LoadBundleFS over a directory on the local filesystem.
+func LoadBundleDir(root string, opts LoadOptions) (*Bundle, error) {
+	info, err := os.Stat(root)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("%q is not a directory", root)
+	}
+	bundle, err := LoadBundleFS(os.DirFS(root), opts)
+	if err != nil {
+		return nil, err
+	}
+	bundle.Metadata.Custom["source"] = root
+	return bundle, nil
+}
+
+// loadFile reads a regular file; directories and other special files yield nil.
+func loadFile(fsys fs.FS, name string) (*File, error) {
+	info, err := fs.Stat(fsys, name)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("stat %q: %w", name, err)
+	}
+	if !info.Mode().IsRegular() {
+		return nil, nil
+	}
+	content, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return nil, fmt.Errorf("read file %q: %w", name, err)
+	}
+	return &File{
+		Path:    "/" + name,
+		Content: content,
+		Mode:    info.Mode().Perm(),
+	}, nil
+}
@@ -0,0 +1,61 @@
+package netjsonconfig
+
+import (
+	"testing"
+	"testing/fstest"
+)
+
+func TestLoadBundleFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"etc/config/network":       {Data: []byte("config interface 'lan'\n")},
+		"etc/config/system":        {Data: []byte("config system 'system'\n")},
+		"etc/config/.network.swp":  {Data: []byte("junk")},
+		"etc/openvpn/ca.crt":       {Data: []byte("CERT"), Mode: 0o600},
+		"etc/openvpn/keys/tls.key": {Data: []byte("nested")},
+		"etc/passwd":               {Data: []byte("root:x:0:0")},
+	}
+
+	bundle, err := LoadBundleFS(fsys, LoadOptions{})
+	if err != nil {
+		t.Fatalf("LoadBundleFS failed: %v", err)
+	}
+	if bundle.Metadata.Format != "uci" {
+		t.Errorf("format: got %q, want uci", bundle.Metadata.Format)
+	}
+	if len(bundle.Packages) != 2 || bundle.Packages[0].Name != "network" || bundle.Packages[1].Name != "system" {
+		t.Fatalf("unexpected packages: %+v", bundle.Packages)
+	}
+	if string(bundle.Packages[0].Content) != "config interface 'lan'\n" {
+		t.Errorf("unexpected network content: %q", bundle.Packages[0].Content)
+	}
+	if len(bundle.Files) != 1 {
+		t.Fatalf("expected 1 additional file, got %+v", bundle.Files)
+	}
+	file := bundle.Files[0]
+	if file.Path != "/etc/openvpn/ca.crt" || string(file.Content) != "CERT" || file.Mode != 0o600 {
+		t.Errorf("unexpected file: %+v", file)
+	}
+}
+
+func TestLoadBundleFS_Options(t *testing.T) {
+	fsys := fstest.MapFS{
+		"overlay/config/network": {Data: []byte("")},
+		"etc/passwd":             {Data: []byte("root:x:0:0")},
+		"etc/openvpn/ca.crt":     {Data: []byte("CERT")},
+	}
+
+	bundle, err := LoadBundleFS(fsys, LoadOptions{ConfigDir: "/overlay/config/", Files: []string{"/etc/passwd"}})
+	if err != nil {
+		t.Fatalf("LoadBundleFS failed: %v", err)
+	}
+	if len(bundle.Packages) != 1 || bundle.Packages[0].Name != "network" {
+		t.Fatalf("unexpected packages: %+v", bundle.Packages)
+	}
+	if len(bundle.Files) != 1 || bundle.Files[0].Path != "/etc/passwd" {
+		t.Fatalf("unexpected files: %+v", bundle.Files)
+	}
+
+	if _, err := LoadBundleFS(fstest.MapFS{"etc/passwd": {}}, LoadOptions{}); err == nil {
+		t.Error("expected error when etc/config is missing")
+	}
+}
@@ -20,6 +20,7 @@ const (
 // RenderOptions controls the forward rendering process (NetJSON → DSL).
 type RenderOptions struct {
 	Mode             RenderMode     // Syntax mode selection
+	TargetVersion    string         // Target firmware version hint used by RenderModeAuto (e.g. "19.07.10")
 	TemplateContext  map[string]any // Variables for template evaluation
 	Strict           bool           // Fail on any warnings if true
 	SkipValidation   bool           // Skip schema validation if true
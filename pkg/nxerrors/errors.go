@@ -27,6 +27,9 @@ const (
 type Error struct {
 	Kind Kind
 	Err  error
+	// File 与 Line 为可选的源位置（如 UCI 包名与行号），Line 为 0 表示未知。
+	File string
+	Line int
 }
 
 // Error 实现 error 接口。
@@ -37,6 +40,9 @@ func (e *Error) Error() string {
 	if e.Err == nil {
 		return string(e.Kind)
 	}
+	if e.Line > 0 {
+		return fmt.Sprintf("%s: %s:%d: %v", e.Kind, e.File, e.Line, e.Err)
+	}
 	return fmt.Sprintf("%s: %v", e.Kind, e.Err)
 }
 
@@ -56,6 +62,14 @@ func New(kind Kind, err error) error {
 	return &Error{Kind: kind, Err: err}
 }
 
+// NewAt 创建带源位置的错误，消息形如 "parse: network:42: ..."。
+func NewAt(kind Kind, file string, line int, err error) error {
+	if err == nil {
+		err = errors.New(string(kind))
+	}
+	return &Error{Kind: kind, Err: err, File: file, Line: line}
+}
+
 var (
 	// ErrNotImplemented 统一指示功能尚未实现。
 	ErrNotImplemented = errors.New("netjsonconfig: not implemented")
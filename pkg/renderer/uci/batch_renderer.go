@@ -0,0 +1,281 @@
+package uci
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	ast "github.com/honeybbq/netjsonconfig/pkg/ast/uci"
+	"github.com/honeybbq/netjsonconfig/pkg/netjsonconfig"
+	"github.com/honeybbq/netjsonconfig/pkg/nxerrors"
+)
+
+// BatchScriptName 是 BatchRenderer 输出的包名。
+const BatchScriptName = "batch"
+
+// BatchRenderer 将 UCI AST 渲染为 `uci batch` 脚本。
+//
+// 与 PlainTextRenderer 替换整个 /etc/config 文件不同，脚本只修改文档中出现的
+// section 与 option，设备上其它配置保持不变：
+//
+//	set network.lan=interface
+//	set network.lan.proto='static'
+//	delete network.lan.dns
+//	add_list network.lan.dns='8.8.8.8'
+//	commit network
+//
+// list 先 delete 再 add_list，因此重复执行脚本结果不变。匿名 section 通过
+// `add` 创建并以 @type[-1] 引用，重复执行会再次创建。
+// 只推送变更时使用 RenderDiff。
+type BatchRenderer struct{}
+
+func NewBatchRenderer() *BatchRenderer {
+	return &BatchRenderer{}
+}
+
+// Render 实现 renderer.Renderer，输出单个名为 BatchScriptName 的包。
+func (r *BatchRenderer) Render(ctx context.Context, doc *ast.Document, opts netjsonconfig.RenderOptions) (*netjsonconfig.Bundle, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if doc == nil {
+		return nil, nxerrors.New(nxerrors.KindRender, fmt.Errorf("uci document is nil"))
+	}
+
+	packages := filterPackages(doc.Packages)
+	if len(packages) == 0 {
+		return nil, nxerrors.New(nxerrors.KindRender, fmt.Errorf("empty document"))
+	}
+
+	var script batchScript
+	for _, pkg := range packages {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		for _, section := range filterSections(pkg.Sections) {
+			script.section(pkg.Name, section)
+		}
+	}
+	for _, pkg := range packages {
+		script.commit(pkg.Name)
+	}
+
+	bundle := newBatchBundle(&script)
+	files, err := convertFiles(doc)
+	if err != nil {
+		return nil, err
+	}
+	bundle.Files = append(bundle.Files, files...)
+
+	return bundle, nil
+}
+
+// RenderDiff 将 ast.Compare 的结果渲染为增量脚本，只包含变更的 section 与 option。
+//
+// 每个包内依次输出：修改已有 section、删除 section（倒序，保证匿名 section 的
+// @type[index] 引用在删除过程中保持有效）、创建新 section。没有差异时脚本为空。
+// 附加文件不参与比较，不会出现在结果中。
+func (r *BatchRenderer) RenderDiff(ctx context.Context, diff *ast.Diff, opts netjsonconfig.RenderOptions) (*netjsonconfig.Bundle, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var script batchScript
+	if diff.Empty() {
+		return newBatchBundle(&script), nil
+	}
+	for _, pkg := range diff.Packages() {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		var deleted []ast.SectionChange
+		for _, change := range diff.Sections {
+			if change.Package != pkg {
+				continue
+			}
+			switch change.Kind {
+			case ast.ChangeModified:
+				ref := currentSectionRef(pkg, change)
+				for _, option := range change.Options {
+					script.option(ref, option)
+				}
+			case ast.ChangeDeleted:
+				deleted = append(deleted, change)
+			}
+		}
+		for i := len(deleted) - 1; i >= 0; i-- {
+			script.delete(currentSectionRef(pkg, deleted[i]))
+		}
+		for _, change := range diff.Sections {
+			if change.Package == pkg && change.Kind == ast.ChangeAdded {
+				script.section(pkg, change.Desired)
+			}
+		}
+	}
+	for _, pkg := range diff.Packages() {
+		script.commit(pkg)
+	}
+
+	return newBatchBundle(&script), nil
+}
+
+func newBatchBundle(script *batchScript) *netjsonconfig.Bundle {
+	bundle := netjsonconfig.NewBundle("uci-batch", "openwrt")
+	bundle.Packages = append(bundle.Packages, netjsonconfig.Package{
+		Name:    BatchScriptName,
+		Content: []byte(script.String()),
+	})
+	return bundle
+}
+
+// batchScript 逐行拼接 uci batch 命令。
+type batchScript struct {
+	b strings.Builder
+}
+
+func (s *batchScript) String() string {
+	return s.b.String()
+}
+
+// section 写出创建 section 及其全部条目的命令。
+func (s *batchScript) section(pkg string, section *ast.Section) {
+	ref := sectionRef(pkg, section)
+	if section.Anonymous || section.Name == "" {
+		fmt.Fprintf(&s.b, "add %s %s\n", pkg, section.Type)
+	} else {
+		fmt.Fprintf(&s.b, "set %s=%s\n", ref, section.Type)
+	}
+
+	entries := section.Entries
+	if !section.Ordered {
+		entries = section.SortedEntries()
+	}
+	cleared := make(map[string]struct{})
+	for _, entry := range entries {
+		if entry.Kind == ast.KindOption {
+			s.set(ref, entry.Key, entry.Value)
+			continue
+		}
+		if _, ok := cleared[entry.Key]; !ok {
+			s.delete(ref + "." + entry.Key)
+			cleared[entry.Key] = struct{}{}
+		}
+		s.addList(ref, entry.Key, entry.Value)
+	}
+}
+
+// option 写出单个 option/list 变更。list 只有删除或追加值时使用 del_list/add_list，
+// 顺序变化或包含重复值时整体重写。
+func (s *batchScript) option(ref string, change ast.OptionChange) {
+	target := ref + "." + change.Key
+	if change.Kind == ast.ChangeDeleted {
+		s.delete(target)
+		return
+	}
+	if !change.DesiredList {
+		if change.CurrentList {
+			s.delete(target)
+		}
+		s.set(ref, change.Key, change.New[0])
+		return
+	}
+
+	if change.Kind == ast.ChangeModified {
+		removed, appended, ok := listDelta(change.Old, change.New)
+		if change.CurrentList && ok {
+			for _, value := range removed {
+				s.delList(ref, change.Key, value)
+			}
+			for _, value := range appended {
+				s.addList(ref, change.Key, value)
+			}
+			return
+		}
+		s.delete(target)
+	}
+	for _, value := range change.New {
+		s.addList(ref, change.Key, value)
+	}
+}
+
+func (s *batchScript) set(ref, key, value string) {
+	fmt.Fprintf(&s.b, "set %s.%s=%s\n", ref, key, quoteBatch(value))
+}
+
+func (s *batchScript) addList(ref, key, value string) {
+	fmt.Fprintf(&s.b, "add_list %s.%s=%s\n", ref, key, quoteBatch(value))
+}
+
+func (s *batchScript) delList(ref, key, value string) {
+	fmt.Fprintf(&s.b, "del_list %s.%s=%s\n", ref, key, quoteBatch(value))
+}
+
+func (s *batchScript) delete(target string) {
+	fmt.Fprintf(&s.b, "delete %s\n", target)
+}
+
+func (s *batchScript) commit(pkg string) {
+	fmt.Fprintf(&s.b, "commit %s\n", pkg)
+}
+
+// sectionRef 返回 section 在 uci 命令中的引用：命名 section 为 pkg.name，
+// 匿名 section 为 pkg.@type[-1]（即刚通过 add 创建的那一个）。
+func sectionRef(pkg string, section *ast.Section) string {
+	if section.Anonymous || section.Name == "" {
+		return fmt.Sprintf("%s.@%s[-1]", pkg, section.Type)
+	}
+	return pkg + "." + section.Name
+}
+
+// currentSectionRef 返回已部署 section 的引用；匿名 section 使用其在同类型中的序号。
+func currentSectionRef(pkg string, change ast.SectionChange) string {
+	if change.Current.Anonymous {
+		return fmt.Sprintf("%s.@%s[%d]", pkg, change.Current.Type, change.Index)
+	}
+	return pkg + "." + change.Current.Name
+}
+
+// listDelta 判断能否仅通过 del_list 与 add_list 将 old 变为 new：
+// 保留的值顺序不变且新值都追加在末尾。del_list 会删除全部同名值，因此含重复值时返回 false。
+func listDelta(old, new []string) (removed, appended []string, ok bool) {
+	if hasDuplicates(old) || hasDuplicates(new) {
+		return nil, nil, false
+	}
+	var kept []string
+	for _, value := range old {
+		if slices.Contains(new, value) {
+			kept = append(kept, value)
+		} else {
+			removed = append(removed, value)
+		}
+	}
+	for _, value := range new {
+		if !slices.Contains(old, value) {
+			appended = append(appended, value)
+		}
+	}
+	return removed, appended, slices.Equal(slices.Concat(kept, appended), new)
+}
+
+func hasDuplicates(values []string) bool {
+	seen := make(map[string]struct{}, len(values))
+	for _, value := range values {
+		if _, ok := seen[value]; ok {
+			return true
+		}
+		seen[value] = struct{}{}
+	}
+	return false
+}
+
+// quoteBatch 使用单引号包裹值，内部的单引号按 shell 习惯转义（与 `uci show` 输出一致）。
+func quoteBatch(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
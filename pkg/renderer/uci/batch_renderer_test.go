@@ -0,0 +1,168 @@
+package uci
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	ast "github.com/honeybbq/netjsonconfig/pkg/ast/uci"
+	"github.com/honeybbq/netjsonconfig/pkg/netjsonconfig"
+)
+
+func TestBatchRenderer(t *testing.T) {
+	lan := ast.NewSection("interface", "lan")
+	lan.SetOption("proto", "static")
+	lan.AddList("dns", "8.8.8.8")
+	lan.AddList("dns", "1.1.1.1")
+	lan.SetOption("description", "it's mine")
+
+	doc := &ast.Document{Packages: []*ast.Package{
+		{Name: "network", Sections: []*ast.Section{lan}},
+		{Name: "system", Sections: []*ast.Section{
+			{Type: "timeserver", Name: "ntp_0", Anonymous: true, Entries: []ast.Entry{{Kind: ast.KindList, Key: "server", Value: "pool.ntp.org"}}},
+		}},
+	}}
+
+	bundle, err := NewBatchRenderer().Render(context.Background(), doc, netjsonconfig.RenderOptions{})
+	if err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	if bundle.Metadata.Format != "uci-batch" || len(bundle.Packages) != 1 || bundle.Packages[0].Name != BatchScriptName {
+		t.Fatalf("unexpected bundle: %+v", bundle.Metadata)
+	}
+
+	want := `set network.lan=interface
+set network.lan.description='it'\''s mine'
+set network.lan.proto='static'
+delete network.lan.dns
+add_list network.lan.dns='8.8.8.8'
+add_list network.lan.dns='1.1.1.1'
+add system timeserver
+delete system.@timeserver[-1].server
+add_list system.@timeserver[-1].server='pool.ntp.org'
+commit network
+commit system
+`
+	if got := string(bundle.Packages[0].Content); got != want {
+		t.Errorf("batch mismatch\n--- got ---\n%s\n--- want ---\n%s", got, want)
+	}
+}
+
+func TestBatchRenderer_ParsedOrder(t *testing.T) {
+	doc, err := parseText(t, "network", "config rule\n\toption src '10.0.0.0/8'\n\toption lookup '100'\n", netjsonconfig.ParseOptions{})
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	bundle, err := NewBatchRenderer().Render(context.Background(), doc, netjsonconfig.RenderOptions{})
+	if err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	want := `add network rule
+set network.@rule[-1].src='10.0.0.0/8'
+set network.@rule[-1].lookup='100'
+commit network
+`
+	if got := string(bundle.Packages[0].Content); got != want {
+		t.Errorf("batch mismatch\n--- got ---\n%s\n--- want ---\n%s", got, want)
+	}
+}
+
+func TestBatchRenderer_RenderDiff(t *testing.T) {
+	current, err := parseText(t, "network", `config interface 'lan'
+	option proto 'static'
+	option ipaddr '192.168.1.1'
+	list dns '8.8.8.8'
+	list dns '9.9.9.9'
+
+config interface 'guest'
+	option proto 'dhcp'
+
+config rule
+	option lookup '100'
+
+config rule
+	option lookup '200'
+`, netjsonconfig.ParseOptions{})
+	if err != nil {
+		t.Fatalf("parse current: %v", err)
+	}
+	desired, err := parseText(t, "network", `config interface 'lan'
+	option proto 'static'
+	option ipaddr '192.168.2.1'
+	list dns '9.9.9.9'
+	list dns '1.1.1.1'
+
+config rule
+	option lookup '200'
+	option priority '10'
+
+config interface 'wan'
+	option proto 'dhcp'
+`, netjsonconfig.ParseOptions{})
+	if err != nil {
+		t.Fatalf("parse desired: %v", err)
+	}
+	// 部署端的 system 包不在 desired 中，不应被改动
+	current.Packages = append(current.Packages, &ast.Package{Name: "system", Sections: []*ast.Section{ast.NewSection("system", "system")}})
+
+	diff := ast.Compare(current, desired)
+	var kinds []string
+	for _, change := range diff.Sections {
+		kinds = append(kinds, change.Kind.String()+" "+change.Name())
+	}
+	wantKinds := "modified lan,modified rule_0,deleted guest,deleted rule_1,added wan"
+	if got := strings.Join(kinds, ","); got != wantKinds {
+		t.Errorf("changes = %s, want %s", got, wantKinds)
+	}
+
+	bundle, err := NewBatchRenderer().RenderDiff(context.Background(), diff, netjsonconfig.RenderOptions{})
+	if err != nil {
+		t.Fatalf("render diff: %v", err)
+	}
+	// desired 中的 rule_0 对应 current 的第一个匿名 rule（lookup 100），
+	// 因此修改 @rule[0]，删除 @rule[1]。
+	want := `set network.lan.ipaddr='192.168.2.1'
+del_list network.lan.dns='8.8.8.8'
+add_list network.lan.dns='1.1.1.1'
+set network.@rule[0].lookup='200'
+set network.@rule[0].priority='10'
+delete network.@rule[1]
+delete network.guest
+set network.wan=interface
+set network.wan.proto='dhcp'
+commit network
+`
+	if got := string(bundle.Packages[0].Content); got != want {
+		t.Errorf("delta mismatch\n--- got ---\n%s\n--- want ---\n%s", got, want)
+	}
+
+	if !ast.Compare(desired, desired).Empty() {
+		t.Error("comparing a document with itself should yield no changes")
+	}
+}
+
+func TestBatchRenderer_RenderDiffListRewrite(t *testing.T) {
+	current := &ast.Document{Packages: []*ast.Package{{Name: "network", Sections: []*ast.Section{ast.NewSection("interface", "lan")}}}}
+	current.Packages[0].Sections[0].SetOption("dns", "8.8.8.8")
+	current.Packages[0].Sections[0].SetList("ports", []string{"lan1", "lan2"})
+
+	desired := &ast.Document{Packages: []*ast.Package{{Name: "network", Sections: []*ast.Section{ast.NewSection("interface", "lan")}}}}
+	desired.Packages[0].Sections[0].SetList("dns", []string{"8.8.8.8", "1.1.1.1"})
+	desired.Packages[0].Sections[0].SetList("ports", []string{"lan2", "lan1"})
+
+	bundle, err := NewBatchRenderer().RenderDiff(context.Background(), ast.Compare(current, desired), netjsonconfig.RenderOptions{})
+	if err != nil {
+		t.Fatalf("render diff: %v", err)
+	}
+	want := `delete network.lan.dns
+add_list network.lan.dns='8.8.8.8'
+add_list network.lan.dns='1.1.1.1'
+delete network.lan.ports
+add_list network.lan.ports='lan2'
+add_list network.lan.ports='lan1'
+commit network
+`
+	if got := string(bundle.Packages[0].Content); got != want {
+		t.Errorf("delta mismatch\n--- got ---\n%s\n--- want ---\n%s", got, want)
+	}
+}
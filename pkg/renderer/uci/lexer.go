@@ -0,0 +1,176 @@
+package uci
+
+import (
+	"fmt"
+	"strings"
+)
+
+// token 是词法分析得到的单个词元，记录起始行列便于报错。
+type token struct {
+	value  string
+	line   int
+	column int
+}
+
+// statement 表示一条逻辑语句（config/option/list/package 行）。
+// comment 为 true 时表示一行注释，tokens[0] 为 '#' 之后的文本。
+type statement struct {
+	tokens  []token
+	line    int
+	comment bool
+}
+
+// syntaxError 描述带行号的词法/语法错误。
+type syntaxError struct {
+	line int
+	msg  string
+}
+
+func (e *syntaxError) Error() string {
+	return fmt.Sprintf("line %d: %s", e.line, e.msg)
+}
+
+// scanStatements 将 UCI 文本切分为逻辑语句。
+//
+// 支持的语法与 libuci 保持一致：
+//   - 单引号内不做任何转义，值中的单引号需先闭合引号，以 \' 写出后再重新打开
+//   - 双引号与未加引号的词元中反斜杠转义下一个字符
+//   - 行尾反斜杠表示续行，引号内允许跨行
+//   - 词元起始处的 # 开始注释，直到行尾；注释作为 comment 语句返回
+//   - 相邻的引号/非引号片段拼接为同一个词元（如 it\'s 与 "it"\'s 等价）
+func scanStatements(content string) ([]statement, error) {
+	var (
+		statements []statement
+		current    statement
+		value      strings.Builder
+		inToken    bool
+		tokLine    int
+		tokColumn  int
+		line       = 1
+		column     = 0
+	)
+
+	startToken := func() {
+		if !inToken {
+			inToken = true
+			tokLine = line
+			tokColumn = column
+		}
+	}
+	endToken := func() {
+		if !inToken {
+			return
+		}
+		if len(current.tokens) == 0 {
+			current.line = tokLine
+		}
+		current.tokens = append(current.tokens, token{value: value.String(), line: tokLine, column: tokColumn})
+		value.Reset()
+		inToken = false
+	}
+	endStatement := func() {
+		endToken()
+		if len(current.tokens) > 0 {
+			statements = append(statements, current)
+		}
+		current = statement{}
+	}
+
+	for i := 0; i < len(content); i++ {
+		c := content[i]
+		column++
+		switch {
+		case c == '\n':
+			endStatement()
+			line++
+			column = 0
+		case c == ' ' || c == '\t' || c == '\r':
+			endToken()
+		case c == '#' && !inToken:
+			endStatement()
+			start := i + 1
+			for i+1 < len(content) && content[i+1] != '\n' {
+				i++
+			}
+			text := strings.TrimRight(content[start:i+1], "\r")
+			statements = append(statements, statement{
+				tokens:  []token{{value: text, line: line, column: column}},
+				line:    line,
+				comment: true,
+			})
+			column += i + 1 - start
+		case c == '\\':
+			startToken()
+			if i+1 >= len(content) {
+				return nil, &syntaxError{line: line, msg: "unexpected end of input after backslash"}
+			}
+			i++
+			if content[i] == '\n' {
+				line++
+				column = 0
+				continue
+			}
+			column++
+			value.WriteByte(content[i])
+		case c == '\'':
+			startToken()
+			startLine := line
+			closed := false
+			for i+1 < len(content) {
+				i++
+				column++
+				ch := content[i]
+				if ch == '\'' {
+					closed = true
+					break
+				}
+				if ch == '\n' {
+					line++
+					column = 0
+				}
+				value.WriteByte(ch)
+			}
+			if !closed {
+				return nil, &syntaxError{line: startLine, msg: "unterminated single-quoted string"}
+			}
+		case c == '"':
+			startToken()
+			startLine := line
+			closed := false
+			for i+1 < len(content) {
+				i++
+				column++
+				ch := content[i]
+				if ch == '\\' && i+1 < len(content) {
+					i++
+					column++
+					if content[i] == '\n' {
+						line++
+						column = 0
+						continue
+					}
+					value.WriteByte(content[i])
+					continue
+				}
+				if ch == '"' {
+					closed = true
+					break
+				}
+				if ch == '\n' {
+					line++
+					column = 0
+				}
+				value.WriteByte(ch)
+			}
+			if !closed {
+				return nil, &syntaxError{line: startLine, msg: "unterminated double-quoted string"}
+			}
+		default:
+			startToken()
+			value.WriteByte(c)
+		}
+	}
+	endStatement()
+
+	return statements, nil
+}
@@ -0,0 +1,257 @@
+package uci
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	commonv1 "github.com/honeybbq/netjson/gen/go/netjson/common/v1"
+
+	ast "github.com/honeybbq/netjsonconfig/pkg/ast/uci"
+	"github.com/honeybbq/netjsonconfig/pkg/netjsonconfig"
+	"github.com/honeybbq/netjsonconfig/pkg/nxerrors"
+)
+
+// PlainTextParser 将 UCI 纯文本（/etc/config/* 文件）解析为 UCI AST。
+type PlainTextParser struct{}
+
+func NewPlainTextParser() *PlainTextParser {
+	return &PlainTextParser{}
+}
+
+// Parse 实现 renderer.Parser。
+//
+// Bundle 中每个 Package 的内容按 UCI 语法解析，包名默认取 Package.Name；
+// 内容中出现的 "package <name>" 行会切换到对应的包（即 `uci export` 输出），
+// 同名包的 section 会合并。`uci show` 格式的输入会被自动识别。
+// 匿名 section 会获得 <type>_<index> 形式的稳定名称，并标记为 Anonymous。
+func (p *PlainTextParser) Parse(ctx context.Context, bundle *netjsonconfig.Bundle, opts netjsonconfig.ParseOptions) (*ast.Document, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+
+	if bundle == nil {
+		return nil, nxerrors.New(nxerrors.KindParse, fmt.Errorf("bundle is nil"))
+	}
+
+	doc := &ast.Document{}
+	index := make(map[string]*ast.Package)
+	for _, pkg := range bundle.Packages {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if err := parsePackage(doc, index, pkg.Name, string(pkg.Content), opts); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, pkg := range doc.Packages {
+		nameAnonymousSections(pkg)
+	}
+
+	for _, file := range bundle.Files {
+		if file.Path == "" {
+			continue
+		}
+		doc.Files = append(doc.Files, &commonv1.IncludedFile{
+			Path:     file.Path,
+			Mode:     formatFileMode(file),
+			Contents: string(file.Content),
+		})
+	}
+
+	if len(doc.Packages) == 0 {
+		return nil, nxerrors.New(nxerrors.KindParse, fmt.Errorf("no uci packages found"))
+	}
+
+	return doc, nil
+}
+
+func parsePackage(doc *ast.Document, index map[string]*ast.Package, name, content string, opts netjsonconfig.ParseOptions) error {
+	statements, err := scanStatements(content)
+	if err != nil {
+		var syntaxErr *syntaxError
+		if errors.As(err, &syntaxErr) {
+			return nxerrors.NewAt(nxerrors.KindParse, displayName(name), syntaxErr.line, errors.New(syntaxErr.msg))
+		}
+		return nxerrors.New(nxerrors.KindParse, err)
+	}
+
+	if isShowFormat(statements) {
+		return parseShow(doc, index, statements, opts)
+	}
+
+	var (
+		pkg     *ast.Package
+		section *ast.Section
+		// pending 收集尚未归属的注释，挂到下一个 config/option/list 上
+		pending []ast.Comment
+	)
+
+	for _, stmt := range statements {
+		if stmt.comment {
+			pending = append(pending, ast.Comment{Text: stmt.tokens[0].value, Pos: tokenPosition(stmt.tokens[0])})
+			continue
+		}
+
+		current := name
+		if pkg != nil {
+			current = pkg.Name
+		}
+		fail := func(format string, args ...any) error {
+			return nxerrors.NewAt(nxerrors.KindParse, displayName(current), stmt.line, fmt.Errorf(format, args...))
+		}
+
+		keyword := stmt.tokens[0].value
+		args := stmt.tokens[1:]
+		var stmtErr error
+		switch keyword {
+		case "package":
+			if len(args) != 1 || !validName(args[0].value) {
+				stmtErr = fail("invalid package statement")
+				break
+			}
+			pkg = selectPackage(doc, index, args[0].value)
+			section = nil
+		case "config":
+			section = nil
+			if len(args) < 1 || len(args) > 2 {
+				stmtErr = fail("config expects a type and an optional name")
+				break
+			}
+			if !validName(args[0].value) {
+				stmtErr = fail("invalid section type %q", args[0].value)
+				break
+			}
+			sectionName := ""
+			if len(args) == 2 {
+				sectionName = args[1].value
+				if !validName(sectionName) {
+					stmtErr = fail("invalid section name %q", sectionName)
+					break
+				}
+			}
+			// 未出现 package 行时使用 Bundle 中的包名
+			if pkg == nil {
+				if name == "" {
+					stmtErr = fail("config section outside of a package")
+					break
+				}
+				pkg = selectPackage(doc, index, name)
+			}
+			section = ast.NewSection(args[0].value, sectionName)
+			section.Pos = tokenPosition(stmt.tokens[0])
+			section.Ordered = true
+			section.Comments, pending = pending, nil
+			pkg.Sections = append(pkg.Sections, section)
+		case "option", "list":
+			if len(args) != 2 {
+				stmtErr = fail("%s expects a name and a value", keyword)
+				break
+			}
+			if !validName(args[0].value) {
+				stmtErr = fail("invalid %s name %q", keyword, args[0].value)
+				break
+			}
+			if section == nil {
+				stmtErr = fail("%s %q outside of a config section", keyword, args[0].value)
+				break
+			}
+			entry := ast.Entry{
+				Kind:     ast.KindOption,
+				Key:      args[0].value,
+				Value:    args[1].value,
+				Pos:      tokenPosition(stmt.tokens[0]),
+				Comments: pending,
+			}
+			if keyword == "list" {
+				entry.Kind = ast.KindList
+			}
+			addEntry(section, entry)
+			pending = nil
+		default:
+			stmtErr = fail("unknown keyword %q", keyword)
+		}
+
+		if stmtErr != nil {
+			if opts.BestEffort {
+				continue
+			}
+			return stmtErr
+		}
+	}
+
+	if pkg != nil && len(pending) > 0 {
+		pkg.Comments = append(pkg.Comments, pending...)
+	}
+
+	return nil
+}
+
+// addEntry 追加条目。与 libuci 一致，重复的 option 覆盖先前的值，
+// 此时保留首次出现的位置，并合并注释。
+func addEntry(section *ast.Section, entry ast.Entry) {
+	if entry.Kind == ast.KindOption {
+		for i := range section.Entries {
+			existing := &section.Entries[i]
+			if existing.Kind == ast.KindOption && existing.Key == entry.Key {
+				existing.Value = entry.Value
+				existing.Comments = append(existing.Comments, entry.Comments...)
+				return
+			}
+		}
+	}
+	section.Entries = append(section.Entries, entry)
+}
+
+func tokenPosition(tok token) ast.Position {
+	return ast.Position{Line: tok.line, Column: tok.column}
+}
+
+// selectPackage 返回同名包，不存在时创建并追加到文档。
+func selectPackage(doc *ast.Document, index map[string]*ast.Package, name string) *ast.Package {
+	if existing, ok := index[name]; ok {
+		return existing
+	}
+	pkg := &ast.Package{Name: name}
+	index[name] = pkg
+	doc.Packages = append(doc.Packages, pkg)
+	return pkg
+}
+
+// validName 校验包名、section 类型/名称与 option 名。
+// 允许 '-'，以兼容 wifi-iface、bridge-vlan 等类型以及含连字符的接口名。
+func validName(value string) bool {
+	if value == "" {
+		return false
+	}
+	for _, r := range value {
+		switch {
+		case r == '_' || r == '-':
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func displayName(name string) string {
+	if name == "" {
+		return "<input>"
+	}
+	return name
+}
+
+func formatFileMode(file netjsonconfig.File) string {
+	mode := file.Mode.Perm()
+	if mode == 0 {
+		mode = 0o644
+	}
+	return fmt.Sprintf("%04o", uint32(mode))
+}
@@ -1,21 +1,13 @@
 package uci
 
-import (
-	"context"
-	"fmt"
-
-	ast "github.com/honeybbq/netjsonconfig/pkg/ast/uci"
-	"github.com/honeybbq/netjsonconfig/pkg/netjsonconfig"
-	"github.com/honeybbq/netjsonconfig/pkg/nxerrors"
-)
-
-// NotImplementedParser 用于尚未实现解析能力的阶段。
-type NotImplementedParser struct{}
-
+// NotImplementedParser 是解析器实现前的占位类型。
+//
+// Deprecated: 解析已实现，请使用 PlainTextParser。
+type NotImplementedParser = PlainTextParser
+
+// NewNotImplementedParser 返回 PlainTextParser。
+//
+// Deprecated: 请使用 NewPlainTextParser。
 func NewNotImplementedParser() *NotImplementedParser {
-	return &NotImplementedParser{}
-}
-
-func (p *NotImplementedParser) Parse(ctx context.Context, bundle *netjsonconfig.NativeBundle, opts netjsonconfig.ParseOptions) (*ast.Document, error) {
-	return nil, nxerrors.New(nxerrors.KindParse, fmt.Errorf("uci parser not implemented"))
+	return NewPlainTextParser()
 }
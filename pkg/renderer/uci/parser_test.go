@@ -0,0 +1,442 @@
+package uci
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	ast "github.com/honeybbq/netjsonconfig/pkg/ast/uci"
+	"github.com/honeybbq/netjsonconfig/pkg/netjsonconfig"
+	"github.com/honeybbq/netjsonconfig/pkg/nxerrors"
+)
+
+func parseText(t *testing.T, name, content string, opts netjsonconfig.ParseOptions) (*ast.Document, error) {
+	t.Helper()
+	bundle := &netjsonconfig.Bundle{
+		Packages: []netjsonconfig.Package{{Name: name, Content: []byte(content)}},
+	}
+	return NewPlainTextParser().Parse(context.Background(), bundle, opts)
+}
+
+func TestParse_QuotingAndComments(t *testing.T) {
+	content := `
+# leading comment
+config interface 'lan'   # trailing comment
+	option proto static
+	option ipaddr "192.168.1.1"
+	option description 'it'\''s "quoted"'
+	option hostname it\'s' 'ok
+	option path 'C:\'
+	list dns '8.8.8.8'
+	list dns '1.1.1.1'
+
+config rule
+	option src 'multi
+line'
+`
+	doc, err := parseText(t, "network", content, netjsonconfig.ParseOptions{})
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if len(doc.Packages) != 1 || doc.Packages[0].Name != "network" {
+		t.Fatalf("unexpected packages: %+v", doc.Packages)
+	}
+	sections := doc.Packages[0].Sections
+	if len(sections) != 2 {
+		t.Fatalf("expected 2 sections, got %d", len(sections))
+	}
+
+	lan := sections[0]
+	if lan.Type != "interface" || lan.Name != "lan" {
+		t.Errorf("unexpected section header: %s %s", lan.Type, lan.Name)
+	}
+	tests := map[string]string{
+		"proto":       "static",
+		"ipaddr":      "192.168.1.1",
+		"description": `it's "quoted"`,
+		"hostname":    "it's ok",
+		"path":        `C:\`,
+	}
+	for key, want := range tests {
+		if got, _ := lan.Option(key); got != want {
+			t.Errorf("option %s: got %q, want %q", key, got, want)
+		}
+	}
+	if got := strings.Join(lan.List("dns"), ","); got != "8.8.8.8,1.1.1.1" {
+		t.Errorf("list dns: got %q", got)
+	}
+
+	rule := sections[1]
+	if rule.Name != "rule_0" || !rule.Anonymous {
+		t.Errorf("anonymous section should get a stable name, got %q (anonymous=%v)", rule.Name, rule.Anonymous)
+	}
+	if got, _ := rule.Option("src"); got != "multi\nline" {
+		t.Errorf("multi-line value: got %q", got)
+	}
+}
+
+func TestParse_PackageLines(t *testing.T) {
+	content := `package system
+
+config system 'system'
+	option hostname 'a'
+
+package network
+
+config interface 'lan'
+	option proto 'dhcp'
+`
+	doc, err := parseText(t, "main", content, netjsonconfig.ParseOptions{})
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if len(doc.Packages) != 2 {
+		t.Fatalf("expected 2 packages, got %d", len(doc.Packages))
+	}
+	if doc.Packages[0].Name != "system" || doc.Packages[1].Name != "network" {
+		t.Errorf("unexpected package names: %s, %s", doc.Packages[0].Name, doc.Packages[1].Name)
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{name: "option outside section", content: "option proto 'static'\n", want: "network:1"},
+		{name: "unterminated quote", content: "config interface 'lan'\n\toption proto 'static\n", want: "network:2"},
+		{name: "unknown keyword", content: "config interface 'lan'\n\n\tfoo bar\n", want: "network:3"},
+		{name: "missing value", content: "config interface 'lan'\n\toption proto\n", want: "network:2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseText(t, "network", tt.content, netjsonconfig.ParseOptions{})
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			var nxErr *nxerrors.Error
+			if !errors.As(err, &nxErr) || nxErr.Kind != nxerrors.KindParse {
+				t.Errorf("expected parse error, got %v", err)
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error %q should mention %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestParse_BestEffort(t *testing.T) {
+	content := "config interface 'lan'\n\tbogus line here\n\toption proto 'static'\n"
+	doc, err := parseText(t, "network", content, netjsonconfig.ParseOptions{BestEffort: true})
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if got, _ := doc.Packages[0].Sections[0].Option("proto"); got != "static" {
+		t.Errorf("expected proto to survive best-effort parse, got %q", got)
+	}
+}
+
+func TestParse_RenderRoundTrip(t *testing.T) {
+	content := `config interface 'lan'
+	option description 'it'\''s here'
+	option path 'C:\'
+	option proto 'static'
+	list dns '8.8.8.8'
+
+config rule 'rule_1'
+	option src '10.0.0.0/8'
+`
+	doc, err := parseText(t, "network", content, netjsonconfig.ParseOptions{})
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	bundle, err := NewPlainTextRenderer().Render(context.Background(), doc, netjsonconfig.RenderOptions{})
+	if err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	if got := string(bundle.Packages[0].Content); got != content {
+		t.Errorf("round trip mismatch\n--- got ---\n%s\n--- want ---\n%s", got, content)
+	}
+}
+
+func TestParse_RenderRoundTripAnonymous(t *testing.T) {
+	content := `config defaults
+	option input 'ACCEPT'
+	option output 'ACCEPT'
+
+config rule
+	option name 'Allow-DHCP-Renew'
+	option src 'wan'
+
+config rule
+	option name 'Allow-Ping'
+	option src 'wan'
+
+config zone 'lan'
+	option name 'lan'
+`
+	doc, err := parseText(t, "firewall", content, netjsonconfig.ParseOptions{})
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if rule := doc.Packages[0].Sections[2]; rule.Name != "rule_1" || !rule.Anonymous {
+		t.Fatalf("expected anonymous rule_1, got %q (anonymous=%v)", rule.Name, rule.Anonymous)
+	}
+	bundle, err := NewPlainTextRenderer().Render(context.Background(), doc, netjsonconfig.RenderOptions{})
+	if err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	if got := string(bundle.Packages[0].Content); got != content {
+		t.Errorf("round trip mismatch\n--- got ---\n%s\n--- want ---\n%s", got, content)
+	}
+}
+
+func TestParse_ExportAndShowProduceSameDocument(t *testing.T) {
+	export := `package network
+
+config interface 'lan'
+	option proto 'static'
+	list dns '8.8.8.8'
+	list dns '1.1.1.1'
+
+config rule
+	option src '10.0.0.0/8'
+
+config rule 'named'
+	option lookup '100'
+
+config rule
+	option description 'it'\''s mine'
+
+package wireless
+
+config wifi-iface
+	option ssid 'OpenWrt'
+`
+	show := `network.lan=interface
+network.lan.proto='static'
+network.lan.dns='8.8.8.8' '1.1.1.1'
+network.@rule[0]=rule
+network.@rule[0].src='10.0.0.0/8'
+network.named=rule
+network.named.lookup='100'
+network.@rule[2]=rule
+network.@rule[2].description='it'\''s mine'
+wireless.@wifi-iface[0]=wifi-iface
+wireless.@wifi-iface[0].ssid='OpenWrt'
+`
+	fromExport, err := parseText(t, "main", export, netjsonconfig.ParseOptions{})
+	if err != nil {
+		t.Fatalf("parse export: %v", err)
+	}
+	fromShow, err := parseText(t, "main", show, netjsonconfig.ParseOptions{})
+	if err != nil {
+		t.Fatalf("parse show: %v", err)
+	}
+	// 两种格式的行号不同，只比较内容
+	stripPositions(fromExport)
+	stripPositions(fromShow)
+	if !reflect.DeepEqual(fromExport, fromShow) {
+		t.Fatalf("documents differ\nexport: %s\nshow:   %s", dumpDocument(fromExport), dumpDocument(fromShow))
+	}
+
+	rules := fromShow.Packages[0].Sections[1:]
+	wantNames := []string{"rule_0", "named", "rule_2"}
+	for i, rule := range rules {
+		if rule.Name != wantNames[i] {
+			t.Errorf("rule %d: got name %q, want %q", i, rule.Name, wantNames[i])
+		}
+	}
+	if got := fromShow.Packages[1].Sections[0].Name; got != "wifi_iface_0" {
+		t.Errorf("wifi-iface stable name: got %q", got)
+	}
+}
+
+// uci show 输出中单值 list 与 option 无法区分，单值按 option 解析
+func TestParse_ShowSingleValueList(t *testing.T) {
+	show := `network.br_lan=device
+network.br_lan.ports='lan1'
+network.br_wan=device
+network.br_wan.ports='wan1' 'wan2'
+`
+	doc, err := parseText(t, "main", show, netjsonconfig.ParseOptions{})
+	if err != nil {
+		t.Fatalf("parse show: %v", err)
+	}
+	single, multi := doc.Packages[0].Sections[0], doc.Packages[0].Sections[1]
+	if got, ok := single.Option("ports"); !ok || got != "lan1" || len(single.List("ports")) != 0 {
+		t.Errorf("single value: got option %q (%v), list %v", got, ok, single.List("ports"))
+	}
+	if got := multi.List("ports"); !reflect.DeepEqual(got, []string{"wan1", "wan2"}) {
+		t.Errorf("multiple values: got list %v", got)
+	}
+}
+
+func TestParse_ShowErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{name: "undeclared section", content: "network.lan=interface\nnetwork.wan.proto='dhcp'\n", want: "network:2"},
+		{name: "type mismatch", content: "network.@rule[0]=route\n", want: "network:1"},
+		{name: "index out of range", content: "network.@rule[0]=rule\nnetwork.@rule[1].src='x'\n", want: "network:2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseText(t, "main", tt.content, netjsonconfig.ParseOptions{})
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error %q should mention %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestParse_PositionsAndComments(t *testing.T) {
+	content := `# managed by operator
+config interface 'lan'
+	option proto 'static'
+	# primary resolver first
+	list dns '8.8.8.8'
+	list dns '1.1.1.1'
+
+# guest network
+config interface 'guest'
+	option proto 'dhcp'
+
+# end of file
+`
+	doc, err := parseText(t, "network", content, netjsonconfig.ParseOptions{})
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	lan := doc.Packages[0].Sections[0]
+	if lan.Pos != (ast.Position{Line: 2, Column: 1}) {
+		t.Errorf("section position: got %s", lan.Pos)
+	}
+	if got := lan.Position("dns"); got != (ast.Position{Line: 5, Column: 2}) {
+		t.Errorf("list position: got %s", got)
+	}
+	if got := lan.Position("missing"); got != lan.Pos {
+		t.Errorf("unknown option should fall back to section position, got %s", got)
+	}
+	if len(lan.Comments) != 1 || lan.Comments[0].Text != " managed by operator" {
+		t.Errorf("section comments: got %+v", lan.Comments)
+	}
+	if got := lan.Entries[1].Comments; len(got) != 1 || got[0].Pos.Line != 4 {
+		t.Errorf("option comments: got %+v", got)
+	}
+
+	bundle, err := NewPlainTextRenderer().Render(context.Background(), doc, netjsonconfig.RenderOptions{})
+	if err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	want := `# managed by operator
+config interface 'lan'
+	option proto 'static'
+	# primary resolver first
+	list dns '8.8.8.8'
+	list dns '1.1.1.1'
+
+# guest network
+config interface 'guest'
+	option proto 'dhcp'
+
+# end of file
+`
+	if got := string(bundle.Packages[0].Content); got != want {
+		t.Errorf("comments not preserved\n--- got ---\n%s\n--- want ---\n%s", got, want)
+	}
+}
+
+func TestParse_PreservesOrder(t *testing.T) {
+	content := `config interface 'wan'
+	option proto 'static'
+	list dns '8.8.8.8'
+	option ipaddr '10.0.0.2'
+	list dns '1.1.1.1'
+	option netmask '255.255.255.0'
+	option proto 'dhcp'
+`
+	doc, err := parseText(t, "network", content, netjsonconfig.ParseOptions{})
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	section := doc.Packages[0].Sections[0]
+	section.SetOption("ipaddr", "10.0.0.3")
+	section.SetOption("gateway", "10.0.0.1")
+
+	bundle, err := NewPlainTextRenderer().Render(context.Background(), doc, netjsonconfig.RenderOptions{})
+	if err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	// 重复的 option 覆盖首次出现的值；修改原地进行，新增条目追加在末尾
+	want := `config interface 'wan'
+	option proto 'dhcp'
+	list dns '8.8.8.8'
+	option ipaddr '10.0.0.3'
+	list dns '1.1.1.1'
+	option netmask '255.255.255.0'
+	option gateway '10.0.0.1'
+`
+	if got := string(bundle.Packages[0].Content); got != want {
+		t.Errorf("order not preserved\n--- got ---\n%s\n--- want ---\n%s", got, want)
+	}
+
+	// builder 生成的 section 仍按名称排序
+	section.Ordered = false
+	bundle, err = NewPlainTextRenderer().Render(context.Background(), doc, netjsonconfig.RenderOptions{})
+	if err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	want = `config interface 'wan'
+	option gateway '10.0.0.1'
+	option ipaddr '10.0.0.3'
+	option netmask '255.255.255.0'
+	option proto 'dhcp'
+	list dns '8.8.8.8'
+	list dns '1.1.1.1'
+`
+	if got := string(bundle.Packages[0].Content); got != want {
+		t.Errorf("sorted rendering mismatch\n--- got ---\n%s\n--- want ---\n%s", got, want)
+	}
+}
+
+func TestParse_ErrorLocation(t *testing.T) {
+	_, err := parseText(t, "network", "config interface 'lan'\n\toption proto 'static'\n\tbogus\n", netjsonconfig.ParseOptions{})
+	var nxErr *nxerrors.Error
+	if !errors.As(err, &nxErr) {
+		t.Fatalf("expected nxerrors.Error, got %v", err)
+	}
+	if nxErr.File != "network" || nxErr.Line != 3 {
+		t.Errorf("location: got %s:%d, want network:3", nxErr.File, nxErr.Line)
+	}
+}
+
+func stripPositions(doc *ast.Document) {
+	for _, pkg := range doc.Packages {
+		for _, section := range pkg.Sections {
+			section.Pos = ast.Position{}
+			for i := range section.Entries {
+				section.Entries[i].Pos = ast.Position{}
+			}
+		}
+	}
+}
+
+func dumpDocument(doc *ast.Document) string {
+	var b strings.Builder
+	for _, pkg := range doc.Packages {
+		for _, section := range pkg.Sections {
+			fmt.Fprintf(&b, "%s.%s=%s %v; ", pkg.Name, section.Name, section.Type, section.Entries)
+		}
+	}
+	return b.String()
+}
@@ -4,7 +4,6 @@ import (
 	"context"
 	"fmt"
 	"io/fs"
-	"sort"
 	"strconv"
 	"strings"
 
@@ -57,23 +56,38 @@ func (r *PlainTextRenderer) Render(ctx context.Context, doc *ast.Document, opts
 			if sectionName == "" {
 				sectionName = fmt.Sprintf("%s_%d", section.Type, sectionIndex)
 			}
-			fmt.Fprintf(&b, "config %s '%s'\n", section.Type, sectionName)
+			writeComments(&b, "", section.Comments)
+			// 匿名 section 按源文件写回不带名称的 config 行，名称仅供内部引用
+			if section.Anonymous {
+				fmt.Fprintf(&b, "config %s\n", section.Type)
+			} else {
+				fmt.Fprintf(&b, "config %s '%s'\n", section.Type, sectionName)
+			}
 
-			for _, key := range sortedKeys(section.Options) {
-				for _, value := range section.Options[key] {
-					fmt.Fprintf(&b, "\toption %s '%s'\n", key, escape(value))
-				}
+			// 解析得到的 section 保持原始顺序，builder 生成的 section 按名称排序
+			entries := section.Entries
+			if !section.Ordered {
+				entries = section.SortedEntries()
 			}
-			for _, key := range sortedKeys(section.Lists) {
-				for _, value := range section.Lists[key] {
-					fmt.Fprintf(&b, "\tlist %s '%s'\n", key, escape(value))
+			for _, entry := range entries {
+				writeComments(&b, "\t", entry.Comments)
+				keyword := "option"
+				if entry.Kind == ast.KindList {
+					keyword = "list"
 				}
+				fmt.Fprintf(&b, "\t%s %s '%s'\n", keyword, entry.Key, escape(entry.Value))
 			}
 
 			if sectionIndex < len(sections)-1 {
 				b.WriteString("\n")
 			}
 		}
+		if len(pkg.Comments) > 0 {
+			if len(sections) > 0 {
+				b.WriteString("\n")
+			}
+			writeComments(&b, "", pkg.Comments)
+		}
 
 		content := b.String()
 		// 确保以换行符结尾
@@ -88,26 +102,35 @@ func (r *PlainTextRenderer) Render(ctx context.Context, doc *ast.Document, opts
 	}
 
 	// 处理附加文件
-	if len(doc.Files) > 0 {
-		for _, file := range doc.Files {
-			if file == nil {
-				continue
-			}
-			mode, err := parseFileMode(file.GetMode())
-			if err != nil {
-				return nil, err
-			}
-			bundle.Files = append(bundle.Files, netjsonconfig.File{
-				Path:    file.GetPath(),
-				Mode:    mode,
-				Content: []byte(file.GetContents()),
-			})
-		}
+	files, err := convertFiles(doc)
+	if err != nil {
+		return nil, err
 	}
+	bundle.Files = append(bundle.Files, files...)
 
 	return bundle, nil
 }
 
+// convertFiles 将文档中的附加文件转换为 Bundle 文件。
+func convertFiles(doc *ast.Document) ([]netjsonconfig.File, error) {
+	var files []netjsonconfig.File
+	for _, file := range doc.Files {
+		if file == nil {
+			continue
+		}
+		mode, err := parseFileMode(file.GetMode())
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, netjsonconfig.File{
+			Path:    file.GetPath(),
+			Mode:    mode,
+			Content: []byte(file.GetContents()),
+		})
+	}
+	return files, nil
+}
+
 func parseFileMode(value string) (fs.FileMode, error) {
 	if value == "" {
 		return 0o644, nil
@@ -136,29 +159,20 @@ func filterSections(sections []*ast.Section) []*ast.Section {
 		if sec == nil || sec.Type == "" {
 			continue
 		}
-		if sec.Options == nil {
-			sec.Options = make(map[string][]string)
-		}
-		if sec.Lists == nil {
-			sec.Lists = make(map[string][]string)
-		}
 		filtered = append(filtered, sec)
 	}
 	return filtered
 }
 
-func sortedKeys(m map[string][]string) []string {
-	if len(m) == 0 {
-		return nil
-	}
-	keys := make([]string, 0, len(m))
-	for key := range m {
-		keys = append(keys, key)
+// writeComments 按原样输出解析时保留的注释行。
+func writeComments(b *strings.Builder, indent string, comments []ast.Comment) {
+	for _, comment := range comments {
+		fmt.Fprintf(b, "%s#%s\n", indent, comment.Text)
 	}
-	sort.Strings(keys)
-	return keys
 }
 
+// escape 转义单引号包裹的值。单引号内没有转义，值中的单引号先闭合引号、
+// 以 \' 写出后再重新打开，与 quoteBatch 一致。
 func escape(value string) string {
-	return strings.ReplaceAll(value, "'", "\\'")
+	return strings.ReplaceAll(value, "'", `'\''`)
 }
@@ -0,0 +1,182 @@
+package uci
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	ast "github.com/honeybbq/netjsonconfig/pkg/ast/uci"
+	"github.com/honeybbq/netjsonconfig/pkg/netjsonconfig"
+	"github.com/honeybbq/netjsonconfig/pkg/nxerrors"
+)
+
+// isShowFormat 判断语句是否来自 `uci show` 输出（package.section[.option]=value）。
+// 普通 UCI 文件与 `uci export` 的首个词元总是关键字，不会包含 '='。
+func isShowFormat(statements []statement) bool {
+	for _, stmt := range statements {
+		if stmt.comment {
+			continue
+		}
+		key, _, ok := strings.Cut(stmt.tokens[0].value, "=")
+		return ok && strings.Contains(key, ".")
+	}
+	return false
+}
+
+// parseShow 解析 `uci show` 输出：
+//
+//	network.lan=interface
+//	network.lan.proto='static'
+//	network.lan.dns='8.8.8.8' '1.1.1.1'
+//	network.@rule[0]=rule
+//	network.@rule[0].src='10.0.0.0/8'
+//
+// 包名取自每行的第一段。`uci show` 不区分单值 list 与 option，
+// 因此只有一个值的行按 option 处理，多个值的行按 list 处理。类型信息无从恢复，
+// 读取 list 的一方需同时接受 option 形式（见 helpers.GetValues）。
+func parseShow(doc *ast.Document, index map[string]*ast.Package, statements []statement, opts netjsonconfig.ParseOptions) error {
+	for _, stmt := range statements {
+		if stmt.comment {
+			continue
+		}
+		key, value, _ := strings.Cut(stmt.tokens[0].value, "=")
+		pkgName, rest, _ := strings.Cut(key, ".")
+		fail := func(format string, args ...any) error {
+			return nxerrors.NewAt(nxerrors.KindParse, displayName(pkgName), stmt.line, fmt.Errorf(format, args...))
+		}
+
+		var stmtErr error
+		sectionRef, option, hasOption := strings.Cut(rest, ".")
+		switch {
+		case !validName(pkgName) || sectionRef == "":
+			stmtErr = fail("invalid key %q", key)
+		case !hasOption:
+			// 声明 section：package.section=type
+			if len(stmt.tokens) != 1 || !validName(value) {
+				stmtErr = fail("invalid section type %q", value)
+				break
+			}
+			pkg := selectPackage(doc, index, pkgName)
+			section := ast.NewSection(value, "")
+			section.Pos = tokenPosition(stmt.tokens[0])
+			section.Ordered = true
+			if typ, _, anonymous := parseSectionRef(sectionRef); anonymous {
+				if typ != value {
+					stmtErr = fail("section %q declared with type %q", sectionRef, value)
+					break
+				}
+			} else if validName(sectionRef) {
+				section.Name = sectionRef
+			} else {
+				stmtErr = fail("invalid section name %q", sectionRef)
+				break
+			}
+			pkg.Sections = append(pkg.Sections, section)
+		default:
+			if !validName(option) {
+				stmtErr = fail("invalid option name %q", option)
+				break
+			}
+			section := lookupSection(index[pkgName], sectionRef)
+			if section == nil {
+				stmtErr = fail("option %q refers to undeclared section %q", option, sectionRef)
+				break
+			}
+			pos := tokenPosition(stmt.tokens[0])
+			if len(stmt.tokens) == 1 {
+				addEntry(section, ast.Entry{Kind: ast.KindOption, Key: option, Value: value, Pos: pos})
+				break
+			}
+			addEntry(section, ast.Entry{Kind: ast.KindList, Key: option, Value: value, Pos: pos})
+			for _, tok := range stmt.tokens[1:] {
+				addEntry(section, ast.Entry{Kind: ast.KindList, Key: option, Value: tok.value, Pos: tokenPosition(tok)})
+			}
+		}
+
+		if stmtErr != nil {
+			if opts.BestEffort {
+				continue
+			}
+			return stmtErr
+		}
+	}
+	return nil
+}
+
+// parseSectionRef 解析 @type[index] 形式的匿名 section 引用。
+func parseSectionRef(ref string) (typ string, idx int, ok bool) {
+	if !strings.HasPrefix(ref, "@") || !strings.HasSuffix(ref, "]") {
+		return "", 0, false
+	}
+	typ, rawIdx, found := strings.Cut(ref[1:len(ref)-1], "[")
+	if !found || !validName(typ) {
+		return "", 0, false
+	}
+	idx, err := strconv.Atoi(rawIdx)
+	if err != nil {
+		return "", 0, false
+	}
+	return typ, idx, true
+}
+
+// lookupSection 按名称或 @type[index] 查找 section。
+// 与 libuci 一致，index 在同类型的全部 section 中计数，负数从末尾开始。
+func lookupSection(pkg *ast.Package, ref string) *ast.Section {
+	if pkg == nil {
+		return nil
+	}
+	typ, idx, anonymous := parseSectionRef(ref)
+	if !anonymous {
+		for _, section := range pkg.Sections {
+			if section.Name == ref {
+				return section
+			}
+		}
+		return nil
+	}
+
+	var matches []*ast.Section
+	for _, section := range pkg.Sections {
+		if section.Type == typ {
+			matches = append(matches, section)
+		}
+	}
+	if idx < 0 {
+		idx += len(matches)
+	}
+	if idx < 0 || idx >= len(matches) {
+		return nil
+	}
+	return matches[idx]
+}
+
+// nameAnonymousSections 为匿名 section 生成稳定名称 <type>_<index>，
+// index 与 `uci show` 中的 @type[index] 一致，因此 export 与 show 两种输入得到相同的名称。
+// 类型中的 '-' 替换为 '_'（wifi-iface → wifi_iface_0）；与已有名称冲突时追加后缀。
+func nameAnonymousSections(pkg *ast.Package) {
+	taken := make(map[string]struct{}, len(pkg.Sections))
+	for _, section := range pkg.Sections {
+		if section.Name != "" {
+			taken[section.Name] = struct{}{}
+		}
+	}
+
+	counters := make(map[string]int)
+	for _, section := range pkg.Sections {
+		idx := counters[section.Type]
+		counters[section.Type]++
+		if section.Name != "" {
+			continue
+		}
+		name := fmt.Sprintf("%s_%d", strings.ReplaceAll(section.Type, "-", "_"), idx)
+		for suffix := 2; ; suffix++ {
+			if _, exists := taken[name]; !exists {
+				break
+			}
+			name = fmt.Sprintf("%s_%d_%d", strings.ReplaceAll(section.Type, "-", "_"), idx, suffix)
+		}
+		taken[name] = struct{}{}
+		section.Name = name
+		section.Anonymous = true
+	}
+}